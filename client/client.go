@@ -2,11 +2,11 @@ package client
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/cmwaters/skychart/types"
 )
@@ -15,14 +15,30 @@ import (
 // parsing the corresponding response
 type Client struct {
 	registryUrl string
+	apiKey      string
 }
 
-func New(registryUrl string) (*Client, error) {
+// Option configures a Client
+type Option func(*Client)
+
+// WithAPIKey authenticates every request to the registry with an API key,
+// sent in the X-API-Key header
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+func New(registryUrl string, opts ...Option) (*Client, error) {
 	_, err := url.Parse(registryUrl)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{registryUrl: registryUrl}, nil
+	c := &Client{registryUrl: registryUrl}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
 }
 
 func (c Client) Chains() ([]string, error) {
@@ -52,6 +68,68 @@ func (c Client) Assets() ([]string, error) {
 	return assets, nil
 }
 
+// ListOptions selects a page of a list endpoint. Zero values use the
+// server's defaults.
+type ListOptions struct {
+	Limit  int
+	Cursor string   // NextCursor of the previous page
+	Sort   string   // "name" or "chain_id"
+	Fields []string // dot separated paths of the fields to return, i.e. "apis.rpc"
+}
+
+func (o ListOptions) query() url.Values {
+	query := url.Values{"expand": {"true"}}
+	if o.Limit > 0 {
+		query.Set("limit", fmt.Sprint(o.Limit))
+	}
+	if o.Cursor != "" {
+		query.Set("cursor", o.Cursor)
+	}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	if len(o.Fields) > 0 {
+		query.Set("fields", strings.Join(o.Fields, ","))
+	}
+	return query
+}
+
+// ChainPage returns a page of chains and the cursor of the next page, which
+// is empty on the last page. Only the selected fields are set if
+// opts.Fields is used.
+func (c Client) ChainPage(opts ListOptions) ([]types.Chain, string, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/chains?%s", c.registryUrl, opts.query().Encode()))
+	if err != nil {
+		return nil, "", err
+	}
+	var resp struct {
+		Items      []types.Chain `json:"items"`
+		NextCursor string        `json:"next_cursor"`
+	}
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Items, resp.NextCursor, nil
+}
+
+// AssetPage returns a page of assets like ChainPage
+func (c Client) AssetPage(opts ListOptions) ([]types.AssetElement, string, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/assets?%s", c.registryUrl, opts.query().Encode()))
+	if err != nil {
+		return nil, "", err
+	}
+	var resp struct {
+		Items      []types.AssetElement `json:"items"`
+		NextCursor string               `json:"next_cursor"`
+	}
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Items, resp.NextCursor, nil
+}
+
 func (c Client) Chain(chain string) (types.Chain, error) {
 	bz, err := c.get(fmt.Sprintf("%s/v1/chain/%s", c.registryUrl, chain))
 	if err != nil {
@@ -78,6 +156,106 @@ func (c Client) Asset(name string) (types.AssetElement, error) {
 	return resp, nil
 }
 
+func (c Client) AssetOrigin(name string) (types.AssetOrigin, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/asset/%s/origin", c.registryUrl, name))
+	if err != nil {
+		return types.AssetOrigin{}, err
+	}
+	var resp types.AssetOrigin
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.AssetOrigin{}, err
+	}
+	return resp, nil
+}
+
+func (c Client) AssetRepresentations(name string) ([]types.AssetRepresentation, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/asset/%s/representations", c.registryUrl, name))
+	if err != nil {
+		return []types.AssetRepresentation{}, err
+	}
+	var resp []types.AssetRepresentation
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return []types.AssetRepresentation{}, err
+	}
+	return resp, nil
+}
+
+// Convert converts an amount between two denom units of the same asset i.e.
+// from "uatom" to "atom"
+func (c Client) Convert(amount, from, to string) (types.Amount, error) {
+	query := url.Values{"amount": {amount}, "from": {from}, "to": {to}}
+	bz, err := c.get(fmt.Sprintf("%s/v1/convert?%s", c.registryUrl, query.Encode()))
+	if err != nil {
+		return types.Amount{}, err
+	}
+	var resp types.Amount
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.Amount{}, err
+	}
+	return resp, nil
+}
+
+// Fees estimates the fee in each of the chain's fee tokens for the given
+// amount of gas
+func (c Client) Fees(chain string, gas uint64) ([]types.FeeEstimate, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/chain/%s/fees?gas=%d", c.registryUrl, chain, gas))
+	if err != nil {
+		return []types.FeeEstimate{}, err
+	}
+	var resp []types.FeeEstimate
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return []types.FeeEstimate{}, err
+	}
+	return resp, nil
+}
+
+// Address identifies the chains that a bech32 address belongs to
+func (c Client) Address(address string) (types.AddressInfo, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/address/%s", c.registryUrl, address))
+	if err != nil {
+		return types.AddressInfo{}, err
+	}
+	var resp types.AddressInfo
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.AddressInfo{}, err
+	}
+	return resp, nil
+}
+
+// ValidateAddress returns an error if the address does not belong to the chain
+func (c Client) ValidateAddress(chain, address string) (types.AddressInfo, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/chain/%s/address/%s", c.registryUrl, chain, address))
+	if err != nil {
+		return types.AddressInfo{}, err
+	}
+	var resp types.AddressInfo
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.AddressInfo{}, err
+	}
+	return resp, nil
+}
+
+// ConvertAddress re-encodes an address with the bech32 prefix of another chain
+func (c Client) ConvertAddress(address, to string) (types.AddressInfo, error) {
+	query := url.Values{"address": {address}, "to": {to}}
+	bz, err := c.get(fmt.Sprintf("%s/v1/address/convert?%s", c.registryUrl, query.Encode()))
+	if err != nil {
+		return types.AddressInfo{}, err
+	}
+	var resp types.AddressInfo
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.AddressInfo{}, err
+	}
+	return resp, nil
+}
+
 func (c Client) RPC(chain string) ([]types.GrpcElement, error) {
 	bz, err := c.get(fmt.Sprintf("%s/v1/chain/%s/endpoints/rpc", c.registryUrl, chain))
 	if err != nil {
@@ -143,21 +321,110 @@ func (c Client) Seeds(chain string) ([]types.PersistentPeerElement, error) {
 	return resp, nil
 }
 
+func (c Client) Bootstrap(chain string) (types.Bootstrap, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/chain/%s/bootstrap", c.registryUrl, chain))
+	if err != nil {
+		return types.Bootstrap{}, err
+	}
+	var resp types.Bootstrap
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.Bootstrap{}, err
+	}
+	return resp, nil
+}
+
+func (c Client) GenesisChecksum(chain string) (types.GenesisChecksum, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/chain/%s/genesis/checksum", c.registryUrl, chain))
+	if err != nil {
+		return types.GenesisChecksum{}, err
+	}
+	var resp types.GenesisChecksum
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.GenesisChecksum{}, err
+	}
+	return resp, nil
+}
+
+// Genesis returns the raw genesis file of a chain
+func (c Client) Genesis(chain string) ([]byte, error) {
+	return c.get(fmt.Sprintf("%s/v1/chain/%s/genesis", c.registryUrl, chain))
+}
+
+func (c Client) Binaries(chain string) ([]types.Binary, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/chain/%s/binaries", c.registryUrl, chain))
+	if err != nil {
+		return []types.Binary{}, err
+	}
+	var resp []types.Binary
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return []types.Binary{}, err
+	}
+	return resp, nil
+}
+
+func (c Client) Binary(chain, os, arch string) (types.Binary, error) {
+	query := url.Values{"os": {os}, "arch": {arch}}
+	bz, err := c.get(fmt.Sprintf("%s/v1/chain/%s/binaries?%s", c.registryUrl, chain, query.Encode()))
+	if err != nil {
+		return types.Binary{}, err
+	}
+	var resp types.Binary
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.Binary{}, err
+	}
+	return resp, nil
+}
+
+func (c Client) ChainProvenance(chain string) (types.ChainProvenance, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/chain/%s/provenance", c.registryUrl, chain))
+	if err != nil {
+		return types.ChainProvenance{}, err
+	}
+	var resp types.ChainProvenance
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.ChainProvenance{}, err
+	}
+	return resp, nil
+}
+
+func (c Client) Status() (types.ServerStatus, error) {
+	bz, err := c.get(fmt.Sprintf("%s/v1/status", c.registryUrl))
+	if err != nil {
+		return types.ServerStatus{}, err
+	}
+	var resp types.ServerStatus
+	err = json.Unmarshal(bz, &resp)
+	if err != nil {
+		return types.ServerStatus{}, err
+	}
+	return resp, nil
+}
+
 func (c Client) get(query string) ([]byte, error) {
-	resp, err := http.Get(query)
+	req, err := http.NewRequest(http.MethodGet, query, nil)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, errors.New("resource not found")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	bodyBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(resp.StatusCode, bodyBytes)
+	}
 	return bodyBytes, nil
 }
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// DownloadBinary downloads the chain's binary for the given platform to path,
+// verifying it against the registry's checksum if one is provided. The file
+// is only created once the download has been verified. The download is
+// aborted when ctx is cancelled.
+func (c Client) DownloadBinary(ctx context.Context, chain, goos, arch, path string) (types.Binary, error) {
+	binary, err := c.Binary(chain, goos, arch)
+	if err != nil {
+		return types.Binary{}, err
+	}
+
+	var checksum hash.Hash
+	if binary.Checksum != "" {
+		checksum, err = newHash(binary.ChecksumType())
+		if err != nil {
+			return types.Binary{}, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, binary.URL, nil)
+	if err != nil {
+		return types.Binary{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return types.Binary{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return types.Binary{}, fmt.Errorf("unexpected status code from %s: %d", binary.URL, resp.StatusCode)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return types.Binary{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	var w io.Writer = tmp
+	if checksum != nil {
+		w = io.MultiWriter(tmp, checksum)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return types.Binary{}, err
+	}
+	if checksum != nil {
+		if sum := hex.EncodeToString(checksum.Sum(nil)); !strings.EqualFold(sum, binary.ChecksumValue()) {
+			return types.Binary{}, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", binary.URL, binary.ChecksumValue(), sum)
+		}
+	}
+
+	if err := tmp.Chmod(0o755); err != nil {
+		return types.Binary{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return types.Binary{}, err
+	}
+	return binary, os.Rename(tmp.Name(), path)
+}
+
+func newHash(checksumType string) (hash.Hash, error) {
+	switch checksumType {
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum type %q", checksumType)
+	}
+}
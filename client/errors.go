@@ -0,0 +1,67 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// Errors that an *Error can be matched against with errors.Is
+var (
+	ErrNotFound     = errors.New("resource not found")
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrInvalid      = errors.New("invalid document")
+	ErrUnavailable  = errors.New("server unavailable")
+)
+
+// Error is returned for every failed request. It holds the status code and
+// the error response of the server.
+type Error struct {
+	StatusCode int
+	types.ErrorResponse
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// Is matches the error against the sentinel errors of this package
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInvalid:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable || e.StatusCode == http.StatusBadGateway
+	default:
+		return false
+	}
+}
+
+// newError decodes the error response of a failed request. Responses that
+// aren't an error response, i.e. from a proxy, only carry the status code.
+func newError(statusCode int, body []byte) *Error {
+	err := &Error{StatusCode: statusCode}
+	if json.Unmarshal(body, &err.ErrorResponse) != nil {
+		err.ErrorResponse = types.ErrorResponse{}
+	}
+	return err
+}
@@ -1,8 +1,9 @@
 module github.com/cmwaters/skychart
 
-go 1.17
+go 1.21
 
 require (
+	github.com/andybalholm/brotli v1.1.1
 	github.com/gorilla/mux v1.8.0
 	github.com/robfig/cron/v3 v3.0.1
 )
@@ -2,48 +2,211 @@ package main
 
 import (
 	"context"
-	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/cmwaters/skychart/server"
 )
 
 const (
 	defaultUpdateFreq = "@daily"
+	usage             = "Usage: skychart [flags] registry-url [listen-addr]"
 )
 
 func main() {
-	registryUrl, listenAddr, err := parseArgs()
+	// run returns rather than exiting so that its deferred cleanup, such as
+	// removing the temporary clone of the registry, always happens
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	genesisDir := flag.String("genesis-cache", "", "directory to cache genesis files in. Genesis files are only served if set")
+	logFormat := flag.String("log-format", "logfmt", "format of log output: json or logfmt")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second, "how long to wait for in-flight requests to complete on shutdown")
+	concurrency := flag.Int("pull-concurrency", 8, "number of chains fetched in parallel when pulling the registry")
+	requestTimeout := flag.Duration("request-timeout", 30*time.Second, "timeout of each request made to the registry when pulling")
+	source := flag.String("source", "github", "where to read the registry from: github (registry-url is owner/repo), git (registry-url is a git remote) or archive (registry-url is the url or path of a tarball or zip)")
+	gitRef := flag.String("git-ref", "", "branch or tag of the registry to track with --source git. Defaults to the remote's default branch")
+	gitDir := flag.String("git-dir", "", "directory to clone the registry into with --source git. Defaults to a new private temporary directory")
+	archiveChecksum := flag.String("archive-checksum", "", "expected sha256 of the archive with --source archive. Any other archive is rejected, so only pin the archive of a fixed version")
+	var overlays overlayFlags
+	flag.Var(&overlays, "overlay", "registry merged over the base registry as name=source:location, i.e. internal=git:https://example.com/registry.git#main. Later overlays take precedence. May be repeated")
+	overridesDir := flag.String("overrides-dir", "", "directory to persist local chain overrides in. Enables the admin API, which requires an admin API key. SKYCHART_ADMIN_TOKEN, if set, is added as one")
+	authConfig := flag.String("auth-config", "", "JSON file of API keys and rate limits")
+	corsOrigins := flag.String("cors-origins", "*", "comma separated origins allowed to make cross-origin requests, or * for any")
+	corsMethods := flag.String("cors-methods", "", "comma separated methods allowed in cross-origin requests. Defaults to GET, HEAD, PUT, PATCH and DELETE")
+	corsHeaders := flag.String("cors-headers", "", "comma separated request headers allowed in cross-origin requests")
+	cacheMaxAge := flag.Duration("cache-max-age", time.Minute, "how long clients and CDNs may cache responses before revalidating them")
+	logLevel := flag.String("log-level", "info", "minimum level of logs: debug, info, warn or error")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "%s\n\n", usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	registryUrl, listenAddr, err := parseArgs(flag.Args())
 	if err != nil {
-		fmt.Print(err)
+		return err
 	}
 
+	logger, err := server.NewLogger(os.Stderr, *logFormat, *logLevel)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	opts := []server.Option{
+		server.WithLogger(logger),
+		server.WithShutdownTimeout(*shutdownTimeout),
+		server.WithConcurrency(*concurrency),
+		server.WithRequestTimeout(*requestTimeout),
+		server.WithCacheMaxAge(*cacheMaxAge),
+	}
+	if *genesisDir != "" {
+		opts = append(opts, server.WithGenesisCache(*genesisDir))
+	}
+	if *overridesDir != "" {
+		opts = append(opts, server.WithOverrides(*overridesDir, os.Getenv("SKYCHART_ADMIN_TOKEN")))
+	}
+	if *authConfig != "" {
+		cfg, err := server.LoadAuthConfig(*authConfig)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, server.WithAuth(cfg))
+	}
+	opts = append(opts, server.WithCORS(server.CORSConfig{
+		AllowedOrigins: splitList(*corsOrigins),
+		AllowedMethods: splitList(*corsMethods),
+		AllowedHeaders: splitList(*corsHeaders),
+	}))
+	if *gitDir == "" && usesGit(*source, overlays) {
+		// a fixed path under the shared temporary directory could be created
+		// by another user beforehand
+		dir, err := os.MkdirTemp("", "skychart-registry-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		*gitDir = filepath.Join(dir, "registry")
+	}
+	var base server.Source
+	switch *source {
+	case "github":
+		base = server.NewGitHubSource(registryUrl, *requestTimeout)
+	case "git":
+		base = server.NewGitSource(registryUrl, *gitRef, *gitDir)
+	case "archive":
+		base = server.NewArchiveSource(registryUrl, *archiveChecksum)
+	default:
+		return fmt.Errorf("unknown source %q", *source)
+	}
+	if len(overlays) > 0 {
+		layers := []server.Layer{{Name: "upstream", Source: base}}
+		for _, overlay := range overlays {
+			layer, err := parseOverlay(overlay, *gitDir, *requestTimeout)
+			if err != nil {
+				return err
+			}
+			layers = append(layers, layer)
+		}
+		base = server.NewLayeredSource(layers...)
+	}
+	opts = append(opts, server.WithSource(base))
+
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
 
-	err = server.Serve(ctx, registryUrl, listenAddr, defaultUpdateFreq)
-	if err != nil {
-		fmt.Print(err)
+	if err := server.Serve(ctx, registryUrl, listenAddr, defaultUpdateFreq, opts...); err != nil {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+	return nil
+}
+
+// overlayFlags collects the registries given by --overlay
+type overlayFlags []string
+
+func (o *overlayFlags) String() string {
+	return strings.Join(*o, ",")
+}
+
+func (o *overlayFlags) Set(value string) error {
+	*o = append(*o, value)
+	return nil
+}
+
+// usesGit reports whether the registry or any overlay is read from a git remote
+func usesGit(source string, overlays overlayFlags) bool {
+	if source == "git" {
+		return true
+	}
+	for _, overlay := range overlays {
+		if _, spec, _ := strings.Cut(overlay, "="); strings.HasPrefix(spec, "git:") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOverlay parses an overlay of the form name=source:location. Git remotes
+// may select a ref with a "#ref" suffix.
+func parseOverlay(value, gitDir string, timeout time.Duration) (server.Layer, error) {
+	name, spec, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return server.Layer{}, fmt.Errorf("invalid overlay %q, expected name=source:location", value)
+	}
+	kind, location, ok := strings.Cut(spec, ":")
+	if !ok || location == "" {
+		return server.Layer{}, fmt.Errorf("invalid overlay %q, expected name=source:location", value)
+	}
+	switch kind {
+	case "github":
+		return server.Layer{Name: name, Source: server.NewGitHubSource(location, timeout)}, nil
+	case "git":
+		remote, ref, _ := strings.Cut(location, "#")
+		return server.Layer{Name: name, Source: server.NewGitSource(remote, ref, gitDir+"-"+name)}, nil
+	case "archive":
+		return server.Layer{Name: name, Source: server.NewArchiveSource(location, "")}, nil
+	default:
+		return server.Layer{}, fmt.Errorf("unknown source %q in overlay %s", kind, name)
+	}
+}
+
+// splitList splits a comma separated flag, ignoring empty elements
+func splitList(value string) []string {
+	var list []string
+	for _, elem := range strings.Split(value, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			list = append(list, elem)
+		}
 	}
+	return list
 }
 
-func parseArgs() (string, string, error) {
-	if len(os.Args) > 3 || len(os.Args) == 1 {
-		return "", "", errors.New("expected 1 or 2 arguments. \n\nUsage: skychart registry-url [listen-addr]")
+func parseArgs(args []string) (string, string, error) {
+	if len(args) > 2 || len(args) == 0 {
+		return "", "", fmt.Errorf("expected 1 or 2 arguments. \n\n%s", usage)
 	}
-	registryUrl := os.Args[1]
+	registryUrl := args[0]
 	_, err := url.Parse(registryUrl)
 	if err != nil {
-		return "", "", fmt.Errorf("unable to parse registry url: %w. \n\nUsage: skychart registry-url [listen-addr]", err)
+		return "", "", fmt.Errorf("unable to parse registry url: %w. \n\n%s", err, usage)
 	}
 
-	if len(os.Args) == 2 {
+	if len(args) == 1 {
 		return registryUrl, "", nil
 	}
 
-	return registryUrl, os.Args[2], nil
+	return registryUrl, args[1], nil
 }
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/hex"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// Address identifies the registered chains that an arbitrary bech32 address
+// belongs to by its human readable part
+func (h Handler) Address(res http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	address, ok := vars["address"]
+	if !ok {
+		badRequest(res, "missing address")
+		return
+	}
+
+	info, err := h.snapshot().addressInfo(address)
+	if err != nil {
+		badRequest(res, "invalid address: %v", err)
+		return
+	}
+	respondWithJSON(res, info)
+}
+
+// ChainAddress validates that an address belongs to the chain
+func (h Handler) ChainAddress(res http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	chainName, ok := vars["chain"]
+	if !ok {
+		badRequest(res, "missing chain")
+		return
+	}
+	address, ok := vars["address"]
+	if !ok {
+		badRequest(res, "missing address")
+		return
+	}
+
+	reg := h.snapshot()
+	exists, chain := reg.findChain(chainName)
+	if !exists {
+		resourceNotFound(res, "chain %s not found", chainName)
+		return
+	}
+	if err := chain.ValidateAddress(address); err != nil {
+		badRequest(res, "invalid address: %v", err)
+		return
+	}
+
+	info, err := reg.addressInfo(address)
+	if err != nil {
+		badRequest(res, "invalid address: %v", err)
+		return
+	}
+	respondWithJSON(res, info)
+}
+
+// ConvertAddress re-encodes an address with the bech32 prefix of the chain
+// given by the "to" query parameter
+func (h Handler) ConvertAddress(res http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	address, to := query.Get("address"), query.Get("to")
+	if address == "" || to == "" {
+		badRequest(res, "the address and to parameters are required")
+		return
+	}
+
+	reg := h.snapshot()
+	exists, chain := reg.findChain(to)
+	if !exists {
+		resourceNotFound(res, "chain %s not found", to)
+		return
+	}
+	converted, err := chain.ConvertAddress(address)
+	if err != nil {
+		badRequest(res, "invalid address: %v", err)
+		return
+	}
+
+	info, err := reg.addressInfo(converted)
+	if err != nil {
+		badRequest(res, "invalid address: %v", err)
+		return
+	}
+	respondWithJSON(res, info)
+}
+
+func (r *registry) addressInfo(address string) (types.AddressInfo, error) {
+	hrp, bz, err := types.DecodeAddress(address)
+	if err != nil {
+		return types.AddressInfo{}, err
+	}
+	prefix, _ := types.SplitPrefix(hrp)
+
+	chains := make([]string, 0)
+	for name, chain := range r.chainList {
+		if chain.Bech32Prefix == hrp || chain.Bech32Prefix == prefix {
+			chains = append(chains, name)
+		}
+	}
+	sort.Strings(chains)
+
+	return types.AddressInfo{
+		Address: address,
+		Prefix:  hrp,
+		Bytes:   hex.EncodeToString(bz),
+		Chains:  chains,
+	}, nil
+}
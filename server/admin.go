@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// maxOverrideSize bounds the size of an override document
+const maxOverrideSize = 1 << 20
+
+// AdminChain reads, replaces, patches or deletes the local override of a
+// chain's chain.json. Overrides are merged over the registry following JSON
+// merge patch semantics. PUT replaces the override, PATCH merges the body into
+// the existing override and DELETE removes it. The merged document is
+// validated against the chain schema before the override is stored. Its
+// chain_name must match the chain and its chain_id must not belong to
+// another chain.
+func (h Handler) AdminChain(res http.ResponseWriter, req *http.Request) {
+	h.adminOverride(res, req, "chain.json", types.ValidateChain, h.checkChain)
+}
+
+// AdminAssets is the equivalent of AdminChain for a chain's assetlist.json.
+// Assets are merged by their base denom.
+func (h Handler) AdminAssets(res http.ResponseWriter, req *http.Request) {
+	h.adminOverride(res, req, "assetlist.json", types.ValidateAssetList, nil)
+}
+
+// adminOverride serves the override of a file of a chain. Merged documents
+// are validated against the schema and then, if check is not nil, against
+// the rest of the registry.
+func (h Handler) adminOverride(res http.ResponseWriter, req *http.Request, file string, validate func([]byte) error, check func(chain string, merged []byte) error) {
+	if h.overrides == nil {
+		resourceNotFound(res, "overrides are not enabled")
+		return
+	}
+	chain, ok := mux.Vars(req)["chain"]
+	if !ok || !validChainName(chain) {
+		badRequest(res, "invalid chain name %q", chain)
+		return
+	}
+	path := chain + "/" + file
+	log := h.requestLog(req)
+
+	switch req.Method {
+	case http.MethodGet:
+		data, err := h.overrides.ReadFile(req.Context(), path)
+		if errors.Is(err, fs.ErrNotExist) {
+			resourceNotFound(res, "no override of %s", path)
+			return
+		}
+		if err != nil {
+			log.Error("reading override", "path", path, "err", err)
+			internalError(res)
+			return
+		}
+		respondWithJSON(res, json.RawMessage(data))
+
+	case http.MethodDelete:
+		removed, err := h.overrides.remove(chain, file)
+		if err != nil {
+			log.Error("removing override", "path", path, "err", err)
+			internalError(res)
+			return
+		}
+		if !removed {
+			resourceNotFound(res, "no override of %s", path)
+			return
+		}
+		log.Info("removed override", "path", path)
+		h.requestPull()
+		noContent(res)
+
+	case http.MethodPut, http.MethodPatch:
+		override, err := decodeObject(http.MaxBytesReader(res, req.Body, maxOverrideSize))
+		if err != nil {
+			badRequest(res, "invalid override: %v", err)
+			return
+		}
+		if req.Method == http.MethodPatch {
+			existing, err := h.readOverride(req.Context(), path)
+			if err != nil {
+				log.Error("reading override", "path", path, "err", err)
+				internalError(res)
+				return
+			}
+			override = mergeJSON(existing, override, "", "local", make(map[string]string))
+		}
+
+		// validate the document as it will be served
+		merged, err := h.mergeOverride(req.Context(), path, override)
+		if err != nil {
+			log.Warn("reading registry", "path", path, "err", err)
+			badGateway(res, "reading %s from the registry failed", path)
+			return
+		}
+		if err := validate(merged); err != nil {
+			var invalid *types.ValidationError
+			if errors.As(err, &invalid) {
+				unprocessable(res, invalid)
+				return
+			}
+			internalError(res)
+			return
+		}
+		if check != nil {
+			if err := check(chain, merged); err != nil {
+				badRequest(res, "invalid override: %v", err)
+				return
+			}
+		}
+
+		data, err := json.MarshalIndent(override, "", "  ")
+		if err != nil {
+			internalError(res)
+			return
+		}
+		if err := h.overrides.write(chain, file, data); err != nil {
+			log.Error("writing override", "path", path, "err", err)
+			internalError(res)
+			return
+		}
+		log.Info("stored override", "path", path)
+		h.requestPull()
+		respondWithJSON(res, json.RawMessage(merged))
+
+	default:
+		methodNotAllowed(res, req)
+	}
+}
+
+// checkChain checks that a merged chain.json names the chain it is stored
+// under and that no other chain of the registry has its chain_id
+func (h Handler) checkChain(chain string, merged []byte) error {
+	var doc struct {
+		ChainName string `json:"chain_name"`
+		ChainID   string `json:"chain_id"`
+	}
+	if err := json.Unmarshal(merged, &doc); err != nil {
+		return err
+	}
+	if doc.ChainName != chain {
+		return fmt.Errorf("chain_name %q does not match chain %q", doc.ChainName, chain)
+	}
+	if owner, ok := h.snapshot().chainById[doc.ChainID]; ok && owner != chain {
+		return fmt.Errorf("chain_id %q belongs to chain %q", doc.ChainID, owner)
+	}
+	return nil
+}
+
+// readOverride returns the decoded override at path or nil if there is none
+func (h Handler) readOverride(ctx context.Context, path string) (interface{}, error) {
+	data, err := h.overrides.ReadFile(ctx, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject(bytes.NewReader(data))
+}
+
+// mergeOverride merges an override over the registry's version of the file
+func (h Handler) mergeOverride(ctx context.Context, path string, override interface{}) ([]byte, error) {
+	var base interface{}
+	data, err := h.upstream.ReadFile(ctx, path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if base, err = decodeObject(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(mergeJSON(base, override, "", "local", make(map[string]string)))
+}
+
+// requestPull schedules a pull to apply changed overrides. Requests made
+// while a pull is pending are coalesced.
+func (h Handler) requestPull() {
+	select {
+	case h.pullRequests <- struct{}{}:
+	default:
+	}
+}
+
+// decodeObject decodes a JSON object, preserving numbers as written
+func decodeObject(r io.Reader) (interface{}, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, errors.New("expected a JSON object")
+	}
+	return obj, nil
+}
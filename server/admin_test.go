@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminChainConsistency(t *testing.T) {
+	h, _, router := newTestHandler(t, map[string]string{
+		"cosmoshub/chain.json": `{"chain_name":"cosmoshub","chain_id":"cosmoshub-4","bech32_prefix":"cosmos"}`,
+		"osmosis/chain.json":   `{"chain_name":"osmosis","chain_id":"osmosis-1","bech32_prefix":"osmo"}`,
+	}, WithOverrides(t.TempDir(), "secret"))
+	root := h.auth.middleware(router)
+
+	testCases := []struct {
+		name   string
+		method string
+		chain  string
+		body   string
+		status int
+	}{
+		{"new chain", http.MethodPut, "newchain", `{"chain_name":"newchain","chain_id":"newchain-1","bech32_prefix":"new"}`, http.StatusOK},
+		{"mismatched chain name", http.MethodPut, "newchain", `{"chain_name":"other","chain_id":"newchain-1","bech32_prefix":"new"}`, http.StatusBadRequest},
+		{"taken chain id", http.MethodPut, "newchain", `{"chain_name":"newchain","chain_id":"cosmoshub-4","bech32_prefix":"new"}`, http.StatusBadRequest},
+		{"patch to a taken chain id", http.MethodPatch, "osmosis", `{"chain_id":"cosmoshub-4"}`, http.StatusBadRequest},
+		{"patch to a mismatched chain name", http.MethodPatch, "osmosis", `{"chain_name":"cosmoshub"}`, http.StatusBadRequest},
+		{"patch keeping the chain id", http.MethodPatch, "cosmoshub", `{"chain_id":"cosmoshub-4","pretty_name":"Cosmos Hub"}`, http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/v1/admin/chain/"+tc.chain, strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			root.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, rec.Code, rec.Body)
+			}
+		})
+	}
+
+	// the rejected overrides were not stored
+	if err := h.Pull(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for id, name := range map[string]string{"cosmoshub-4": "cosmoshub", "osmosis-1": "osmosis", "newchain-1": "newchain"} {
+		rec := serveTest(router, http.MethodGet, "/v1/chain/"+id)
+		var chain struct {
+			ChainName string `json:"chain_name"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &chain); err != nil {
+			t.Fatal(err)
+		}
+		if chain.ChainName != name {
+			t.Fatalf("expected %s to be served from %s, got %s", id, name, chain.ChainName)
+		}
+	}
+}
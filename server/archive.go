@@ -0,0 +1,323 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxArchiveSize bounds the size of a registry archive as downloaded
+	maxArchiveSize = 1 << 30
+	// maxArchiveFileSize bounds the decompressed size of a single JSON file
+	// in an archive
+	maxArchiveFileSize = 32 << 20
+	// maxArchiveFilesSize bounds the decompressed size of all JSON files in
+	// an archive, which are kept in memory
+	maxArchiveFilesSize = 1 << 30
+)
+
+// ArchiveSource reads the registry from a tarball (optionally gzipped) or zip
+// archive, such as GitHub's codeload archive of a ref. The archive is
+// streamed once per update, zip archives through a temporary file, and only
+// the JSON files of each chain are kept in memory. A single top level
+// directory, as found in GitHub archives, is stripped.
+type ArchiveSource struct {
+	location string // url or local path of the archive
+	checksum string // expected hex encoded sha256 of the archive. Optional
+
+	mtx      sync.RWMutex
+	etag     string
+	revision string
+	chains   []string
+	files    map[string][]byte
+}
+
+var _ Source = (*ArchiveSource)(nil)
+
+// NewArchiveSource creates a source that reads the archive at location, which
+// may be an http(s) url or a local path. If checksum is not empty, archives
+// whose sha256 doesn't match it are rejected. A pinned checksum means the
+// registry can never update, so it should only be used with the location of a
+// fixed version, i.e. the archive of a tag or commit.
+func NewArchiveSource(location, checksum string) *ArchiveSource {
+	return &ArchiveSource{
+		location: location,
+		checksum: strings.ToLower(strings.TrimPrefix(checksum, "sha256:")),
+		files:    make(map[string][]byte),
+	}
+}
+
+// String describes the location of the archive
+func (s *ArchiveSource) String() string {
+	return "archive:" + redactURL(s.location)
+}
+
+// Revision downloads and unpacks the archive, returning its sha256. If the
+// server reports that the archive hasn't changed since the last download, the
+// previous revision is returned.
+func (s *ArchiveSource) Revision(ctx context.Context) (string, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	body, etag, err := s.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	if body == nil {
+		// not modified
+		return s.revision, nil
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	r := io.TeeReader(&cappedReader{r: body, remaining: maxArchiveSize}, hash)
+	files, err := unpackArchive(r, s.location)
+	if err != nil {
+		return "", fmt.Errorf("unpacking %s: %w", s.location, err)
+	}
+	// the checksum covers the whole archive, including anything after the
+	// end of the tar stream
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return "", fmt.Errorf("reading %s: %w", s.location, err)
+	}
+	revision := hex.EncodeToString(hash.Sum(nil))
+	if s.checksum != "" && s.checksum != revision {
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", s.location, s.checksum, revision)
+	}
+	files = stripTopLevelDir(files)
+
+	seen := make(map[string]bool)
+	chains := make([]string, 0)
+	for name := range files {
+		dir, _, ok := strings.Cut(name, "/")
+		if ok && !seen[dir] && isChainDir(dir) {
+			seen[dir] = true
+			chains = append(chains, dir)
+		}
+	}
+	sort.Strings(chains)
+
+	s.etag, s.revision, s.chains, s.files = etag, revision, chains, files
+	return revision, nil
+}
+
+// Chains lists the chain directories of the last unpacked archive
+func (s *ArchiveSource) Chains(ctx context.Context) ([]string, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.chains, nil
+}
+
+// ReadFile returns a JSON file from the last unpacked archive
+func (s *ArchiveSource) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	data, ok := s.files[path.Clean(name)]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+// open opens the archive for reading, returning a nil body if it hasn't been
+// modified since the last download
+func (s *ArchiveSource) open(ctx context.Context) (io.ReadCloser, string, error) {
+	if !strings.HasPrefix(s.location, "http://") && !strings.HasPrefix(s.location, "https://") {
+		file, err := os.Open(s.location)
+		return file, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.location, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if s.etag != "" && s.revision != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if resp.ContentLength > maxArchiveSize {
+			resp.Body.Close()
+			return nil, "", fmt.Errorf("archive exceeds %d bytes", maxArchiveSize)
+		}
+		return resp.Body, resp.Header.Get("ETag"), nil
+	case http.StatusNotModified:
+		resp.Body.Close()
+		return nil, s.etag, nil
+	default:
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("unexpected status code for %s: %d", s.location, resp.StatusCode)
+	}
+}
+
+// cappedReader fails once more than remaining bytes have been read
+type cappedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	if c.remaining < 0 {
+		return n, fmt.Errorf("archive exceeds %d bytes", maxArchiveSize)
+	}
+	return n, err
+}
+
+// archiveBudget bounds the decompressed size of the files read from an
+// archive, so that a small archive can't expand to exhaust memory
+type archiveBudget struct {
+	remaining int64
+}
+
+func (b *archiveBudget) read(r io.Reader, name string) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxArchiveFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArchiveFileSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", name, maxArchiveFileSize)
+	}
+	b.remaining -= int64(len(data))
+	if b.remaining < 0 {
+		return nil, fmt.Errorf("files exceed %d bytes", maxArchiveFilesSize)
+	}
+	return data, nil
+}
+
+// unpackArchive returns the JSON files of a zip, tar or gzipped tar archive
+// keyed by their slash separated path. The format is detected from the
+// content, falling back to the name.
+func unpackArchive(r io.Reader, name string) (map[string][]byte, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	budget := &archiveBudget{remaining: maxArchiveFilesSize}
+	if bytes.HasPrefix(magic, []byte("PK\x03\x04")) || strings.HasSuffix(name, ".zip") {
+		return unpackZip(br, budget)
+	}
+	if bytes.HasPrefix(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		return unpackTar(gz, budget)
+	}
+	return unpackTar(br, budget)
+}
+
+// unpackZip spools a zip archive to a temporary file, as its directory is at
+// the end, and reads its JSON files
+func unpackZip(r io.Reader, budget *archiveBudget) (map[string][]byte, error) {
+	tmp, err := os.CreateTemp("", "skychart-archive-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	for _, file := range archive.File {
+		name, ok := archivePath(file.Name)
+		if !ok || file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := budget.read(rc, name)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+	return files, nil
+}
+
+func unpackTar(r io.Reader, budget *archiveBudget) (map[string][]byte, error) {
+	archive := tar.NewReader(r)
+	files := make(map[string][]byte)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name, ok := archivePath(header.Name)
+		if !ok || header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := budget.read(archive, name)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+}
+
+// archivePath cleans the path of an archive entry, reporting whether it is a
+// JSON file that should be kept
+func archivePath(name string) (string, bool) {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(name) || strings.HasPrefix(name, "../") || path.Ext(name) != ".json" {
+		return "", false
+	}
+	return name, true
+}
+
+// stripTopLevelDir removes the leading directory shared by all files, as in
+// archives of a GitHub ref, unless it is itself a chain directory
+func stripTopLevelDir(files map[string][]byte) map[string][]byte {
+	prefix := ""
+	for name := range files {
+		dir, _, ok := strings.Cut(name, "/")
+		if !ok || (prefix != "" && dir != prefix) {
+			return files
+		}
+		prefix = dir
+	}
+	if prefix == "" {
+		return files
+	}
+	if _, ok := files[prefix+"/chain.json"]; ok {
+		return files
+	}
+	stripped := make(map[string][]byte, len(files))
+	for name, content := range files {
+		stripped[strings.TrimPrefix(name, prefix+"/")] = content
+	}
+	return stripped
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// archiveEntry is a file of a test archive. Entries without a type are
+// regular files. The content of a link is its target.
+type archiveEntry struct {
+	name     string
+	content  string
+	typeflag byte
+}
+
+// tarArchive returns a tarball of the entries, gzipped if compress is set
+func tarArchive(t *testing.T, compress bool, entries ...archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(&buf)
+	if compress {
+		tw = tar.NewWriter(gz)
+	}
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.content)), Typeflag: entry.typeflag}
+		switch entry.typeflag {
+		case 0:
+			header.Typeflag = tar.TypeReg
+		case tar.TypeSymlink, tar.TypeLink:
+			header.Linkname, header.Size = entry.content, 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Size > 0 {
+			if _, err := tw.Write([]byte(entry.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if compress {
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, entries ...archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := zw.Create(entry.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// writeArchive writes an archive to a temporary file, returning its path
+// and sha256
+func writeArchive(t *testing.T, name string, data []byte) (string, string) {
+	t.Helper()
+	location := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(location, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	return location, hex.EncodeToString(sum[:])
+}
+
+func TestArchiveSource(t *testing.T) {
+	entries := []archiveEntry{
+		{name: "chain-registry-main/", typeflag: tar.TypeDir},
+		{name: "chain-registry-main/README.md", content: "# registry"},
+		{name: "chain-registry-main/cosmoshub/chain.json", content: `{"chain_name":"cosmoshub"}`},
+		{name: "chain-registry-main/cosmoshub/assetlist.json", content: `{"chain_name":"cosmoshub","assets":[]}`},
+		{name: "chain-registry-main/osmosis/chain.json", content: `{"chain_name":"osmosis"}`},
+		{name: "chain-registry-main/_IBC/osmosis-cosmoshub.json", content: `{}`},
+	}
+	archives := []struct {
+		name string
+		data []byte
+	}{
+		{"registry.tar.gz", tarArchive(t, true, entries...)},
+		{"registry.tar", tarArchive(t, false, entries...)},
+		{"registry.zip", zipArchive(t, entries[1:]...)},
+		// the format is detected from the content, not the name
+		{"registry", zipArchive(t, entries[1:]...)},
+	}
+	ctx := context.Background()
+	for _, archive := range archives {
+		t.Run(archive.name, func(t *testing.T) {
+			location, sum := writeArchive(t, archive.name, archive.data)
+			source := NewArchiveSource(location, "")
+			revision, err := source.Revision(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if revision != sum {
+				t.Fatalf("expected the revision to be the sha256 of the archive %s, got %s", sum, revision)
+			}
+			chains, err := source.Chains(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(chains, []string{"cosmoshub", "osmosis"}) {
+				t.Fatalf("unexpected chains %v", chains)
+			}
+			data, err := source.ReadFile(ctx, "cosmoshub/chain.json")
+			if err != nil || string(data) != `{"chain_name":"cosmoshub"}` {
+				t.Fatalf("unexpected chain.json %s, %v", data, err)
+			}
+			if _, err := source.ReadFile(ctx, "_IBC/osmosis-cosmoshub.json"); err != nil {
+				t.Fatalf("expected JSON files outside chain directories to be kept, got %v", err)
+			}
+			if _, err := source.ReadFile(ctx, "README.md"); !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("expected files other than JSON to be skipped, got %v", err)
+			}
+		})
+	}
+}
+
+func TestArchiveSourceEntries(t *testing.T) {
+	testCases := []struct {
+		name    string
+		entries []archiveEntry
+		files   []string
+	}{
+		{
+			"single chain directory is not stripped",
+			[]archiveEntry{{name: "cosmoshub/chain.json", content: `{}`}},
+			[]string{"cosmoshub/chain.json"},
+		},
+		{
+			"no common directory",
+			[]archiveEntry{{name: "cosmoshub/chain.json", content: `{}`}, {name: "osmosis/chain.json", content: `{}`}},
+			[]string{"cosmoshub/chain.json", "osmosis/chain.json"},
+		},
+		{
+			"leading dot",
+			[]archiveEntry{{name: "./registry/cosmoshub/chain.json", content: `{}`}},
+			[]string{"cosmoshub/chain.json"},
+		},
+		{
+			"path traversal",
+			[]archiveEntry{
+				{name: "registry/cosmoshub/chain.json", content: `{}`},
+				{name: "../osmosis/chain.json", content: `{}`},
+				{name: "registry/../../juno/chain.json", content: `{}`},
+				{name: "/etc/akash/chain.json", content: `{}`},
+			},
+			[]string{"cosmoshub/chain.json"},
+		},
+		{
+			"traversal within the archive",
+			[]archiveEntry{{name: "registry/other/../cosmoshub/chain.json", content: `{}`}},
+			[]string{"cosmoshub/chain.json"},
+		},
+		{
+			"links are skipped",
+			[]archiveEntry{
+				{name: "registry/cosmoshub/chain.json", content: `{}`},
+				{name: "registry/osmosis/chain.json", content: "/etc/passwd", typeflag: tar.TypeSymlink},
+				{name: "registry/juno/chain.json", content: "registry/cosmoshub/chain.json", typeflag: tar.TypeLink},
+			},
+			[]string{"cosmoshub/chain.json"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			location, _ := writeArchive(t, "registry.tar.gz", tarArchive(t, true, tc.entries...))
+			source := NewArchiveSource(location, "")
+			if _, err := source.Revision(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			files := sortedKeys(source.files)
+			if !reflect.DeepEqual(files, tc.files) {
+				t.Fatalf("expected files %v, got %v", tc.files, files)
+			}
+		})
+	}
+}
+
+func TestArchiveSourceChecksum(t *testing.T) {
+	location, sum := writeArchive(t, "registry.tar.gz", tarArchive(t, true, archiveEntry{name: "cosmoshub/chain.json", content: `{}`}))
+	ctx := context.Background()
+
+	if _, err := NewArchiveSource(location, "sha256:"+strings.ToUpper(sum)).Revision(ctx); err != nil {
+		t.Fatalf("expected the checksum to match, got %v", err)
+	}
+	mismatch := strings.Repeat("0", len(sum))
+	source := NewArchiveSource(location, mismatch)
+	_, err := source.Revision(ctx)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if chains, _ := source.Chains(ctx); len(chains) != 0 {
+		t.Fatalf("expected a mismatched archive not to be used, got chains %v", chains)
+	}
+}
+
+func TestArchiveBudget(t *testing.T) {
+	// a single file larger than the limit compresses to a small archive
+	large := archiveEntry{name: "cosmoshub/chain.json", content: strings.Repeat(" ", maxArchiveFileSize+1)}
+	location, _ := writeArchive(t, "registry.tar.gz", tarArchive(t, true, large))
+	_, err := NewArchiveSource(location, "").Revision(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "cosmoshub/chain.json exceeds") {
+		t.Fatalf("expected the file to exceed the limit, got %v", err)
+	}
+
+	// the budget covers all files together
+	budget := &archiveBudget{remaining: 10}
+	if _, err := budget.read(strings.NewReader("123456"), "a.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := budget.read(strings.NewReader("1234"), "b.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := budget.read(strings.NewReader("1"), "c.json"); err == nil {
+		t.Fatal("expected the files to exceed the budget")
+	}
+}
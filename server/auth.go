@@ -0,0 +1,296 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope is the level of access granted to an API key
+type Scope string
+
+const (
+	// ScopeRead allows access to the public API
+	ScopeRead Scope = "read"
+	// ScopeAdmin additionally allows access to the admin API
+	ScopeAdmin Scope = "admin"
+)
+
+// APIKeyHeader is the header that API keys are read from. Keys may also be
+// given as a bearer token or with the "api_key" query parameter.
+const APIKeyHeader = "X-API-Key"
+
+const (
+	// bucketIdleTimeout is how long an unused rate limit bucket is kept
+	bucketIdleTimeout = 10 * time.Minute
+	// maxBuckets bounds the number of rate limit buckets. Once reached, new
+	// clients share a single overflow bucket until idle buckets expire.
+	maxBuckets = 100000
+	// overflowBucket is the id of the bucket shared once maxBuckets is reached
+	overflowBucket = "overflow"
+)
+
+// RateLimit is a token bucket rate limit. A zero rate is unlimited.
+type RateLimit struct {
+	Rate  float64 `json:"rate"`  // requests per second
+	Burst int     `json:"burst"` // maximum number of requests at once
+}
+
+// APIKey grants access to the API with a scope and rate limit
+type APIKey struct {
+	Name  string `json:"name"`
+	Key   string `json:"key"`
+	Scope Scope  `json:"scope"`
+	RateLimit
+}
+
+// AuthConfig configures API keys and rate limits
+type AuthConfig struct {
+	Keys []APIKey `json:"keys"`
+	// RequireKey rejects requests without an API key. Health checks are
+	// always allowed.
+	RequireKey bool `json:"require_key"`
+	// Anonymous is the rate limit of requests without a valid API key,
+	// applied per client IP. Requests with an unknown key are charged to it
+	// too, which throttles guessing keys.
+	Anonymous RateLimit `json:"anonymous"`
+	// TrustedProxies is the number of proxies in front of the server that
+	// append to the X-Forwarded-For header. The client IP is the address
+	// that the outermost of them saw. Zero ignores the header.
+	TrustedProxies int `json:"trusted_proxies"`
+}
+
+// LoadAuthConfig reads an AuthConfig from a JSON file
+func LoadAuthConfig(path string) (AuthConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return AuthConfig{}, err
+	}
+	var cfg AuthConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return AuthConfig{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, cfg.validate()
+}
+
+func (c AuthConfig) validate() error {
+	names := make(map[string]bool)
+	for idx, key := range c.Keys {
+		if key.Key == "" {
+			return fmt.Errorf("key %d has no key", idx)
+		}
+		if key.Name == "" {
+			return fmt.Errorf("key %d has no name", idx)
+		}
+		if names[key.Name] {
+			return fmt.Errorf("duplicate key name %s", key.Name)
+		}
+		names[key.Name] = true
+		if key.Scope != ScopeRead && key.Scope != ScopeAdmin {
+			return fmt.Errorf("key %s has invalid scope %q", key.Name, key.Scope)
+		}
+		if err := key.RateLimit.validate(); err != nil {
+			return fmt.Errorf("key %s: %w", key.Name, err)
+		}
+	}
+	if err := c.Anonymous.validate(); err != nil {
+		return fmt.Errorf("anonymous: %w", err)
+	}
+	if c.TrustedProxies < 0 {
+		return fmt.Errorf("invalid trusted proxies %d", c.TrustedProxies)
+	}
+	return nil
+}
+
+func (l RateLimit) validate() error {
+	if l.Rate < 0 || l.Burst < 0 || (l.Rate > 0 && l.Burst == 0) {
+		return fmt.Errorf("invalid rate limit: rate %v, burst %d", l.Rate, l.Burst)
+	}
+	return nil
+}
+
+type identityKey struct{}
+
+// authenticator identifies requests by their API key and enforces rate
+// limits and scopes
+type authenticator struct {
+	cfg AuthConfig
+
+	mtx       sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newAuthenticator(cfg AuthConfig) *authenticator {
+	return &authenticator{cfg: cfg, buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+// hasScope reports whether any key grants scope
+func (a *authenticator) hasScope(scope Scope) bool {
+	for _, key := range a.cfg.Keys {
+		if key.Scope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// middleware authenticates requests, rejecting unknown keys and enforcing
+// rate limits. Requests without a valid key are charged to the bucket of
+// their IP before they are rejected. Health checks are exempt.
+func (a *authenticator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/healthz" || req.URL.Path == "/readyz" || req.Method == http.MethodOptions {
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		provided := apiKeyFromRequest(req)
+		key, ok := a.lookup(provided)
+		if ok {
+			if ok, retryAfter := a.allow("key:"+key.Name, key.RateLimit, time.Now()); !ok {
+				tooManyRequests(res, retryAfter)
+				return
+			}
+			next.ServeHTTP(res, req.WithContext(context.WithValue(req.Context(), identityKey{}, key)))
+			return
+		}
+
+		if ok, retryAfter := a.allow("ip:"+a.clientIP(req), a.cfg.Anonymous, time.Now()); !ok {
+			tooManyRequests(res, retryAfter)
+			return
+		}
+		switch {
+		case provided != "":
+			unauthorized(res, "invalid API key")
+		case a.cfg.RequireKey:
+			unauthorized(res, "an API key is required")
+		default:
+			next.ServeHTTP(res, req)
+		}
+	})
+}
+
+// requireScope only allows requests whose API key grants scope
+func requireScope(scope Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			key, ok := req.Context().Value(identityKey{}).(APIKey)
+			if !ok {
+				unauthorized(res, "an API key is required")
+				return
+			}
+			if key.Scope != scope && key.Scope != ScopeAdmin {
+				forbidden(res, fmt.Sprintf("the API key does not have %s scope", scope))
+				return
+			}
+			next.ServeHTTP(res, req)
+		})
+	}
+}
+
+func (a *authenticator) lookup(provided string) (APIKey, bool) {
+	if provided == "" {
+		return APIKey{}, false
+	}
+	for _, key := range a.cfg.Keys {
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(key.Key)) == 1 {
+			return key, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// allow takes a token from the bucket of id, returning how long to wait
+// before retrying if there are none left
+func (a *authenticator) allow(id string, limit RateLimit, now time.Time) (bool, time.Duration) {
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if now.Sub(a.lastSweep) > bucketIdleTimeout {
+		for bucketID, b := range a.buckets {
+			if now.Sub(b.last) > bucketIdleTimeout {
+				delete(a.buckets, bucketID)
+			}
+		}
+		a.lastSweep = now
+	}
+
+	b, ok := a.buckets[id]
+	if !ok {
+		if len(a.buckets) >= maxBuckets {
+			id = overflowBucket
+			b, ok = a.buckets[id]
+		}
+		if !ok {
+			b = &bucket{tokens: float64(limit.Burst), last: now}
+			a.buckets[id] = b
+		}
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+}
+
+// clientIP returns the IP of the client. Behind trusted proxies it is the
+// rightmost address of X-Forwarded-For that none of them added, as any
+// addresses to its left are set by the client.
+func (a *authenticator) clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	if a.cfg.TrustedProxies == 0 {
+		return host
+	}
+
+	var hops []string
+	for _, value := range req.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	if len(hops) == 0 {
+		return host
+	}
+	// every trusted proxy appends the address it received the request from,
+	// so the outermost one appended the client's
+	idx := len(hops) - a.cfg.TrustedProxies
+	if idx < 0 {
+		idx = 0
+	}
+	if net.ParseIP(hops[idx]) == nil {
+		return host
+	}
+	return hops[idx]
+}
+
+func apiKeyFromRequest(req *http.Request) string {
+	if key := req.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return req.URL.Query().Get("api_key")
+}
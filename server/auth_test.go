@@ -0,0 +1,165 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	a := newAuthenticator(AuthConfig{})
+	limit := RateLimit{Rate: 2, Burst: 3}
+	start := time.Now()
+
+	steps := []struct {
+		name       string
+		id         string
+		after      time.Duration
+		allowed    bool
+		retryAfter time.Duration
+	}{
+		{"burst", "a", 0, true, 0},
+		{"burst", "a", 0, true, 0},
+		{"burst", "a", 0, true, 0},
+		{"empty", "a", 0, false, 500 * time.Millisecond},
+		{"other bucket", "b", 0, true, 0},
+		{"partially refilled", "a", 250 * time.Millisecond, false, 250 * time.Millisecond},
+		{"refilled", "a", 500 * time.Millisecond, true, 0},
+		{"empty again", "a", 500 * time.Millisecond, false, 500 * time.Millisecond},
+		{"refill is capped at burst", "a", time.Minute, true, 0},
+		{"refill is capped at burst", "a", time.Minute, true, 0},
+		{"refill is capped at burst", "a", time.Minute, true, 0},
+		{"refill is capped at burst", "a", time.Minute, false, 500 * time.Millisecond},
+	}
+	for idx, step := range steps {
+		allowed, retryAfter := a.allow(step.id, limit, start.Add(step.after))
+		if allowed != step.allowed || retryAfter != step.retryAfter {
+			t.Fatalf("step %d (%s): expected %v, %v, got %v, %v", idx, step.name, step.allowed, step.retryAfter, allowed, retryAfter)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		if allowed, _ := a.allow("unlimited", RateLimit{}, start); !allowed {
+			t.Fatal("expected a zero rate to be unlimited")
+		}
+	}
+}
+
+func TestAllowOverflow(t *testing.T) {
+	a := newAuthenticator(AuthConfig{})
+	limit := RateLimit{Rate: 1, Burst: 1}
+	now := time.Now()
+	for i := 0; i < maxBuckets; i++ {
+		a.allow(fmt.Sprint("ip:", i), limit, now)
+	}
+	if len(a.buckets) != maxBuckets {
+		t.Fatalf("expected %d buckets, got %d", maxBuckets, len(a.buckets))
+	}
+
+	// new clients share the overflow bucket
+	if allowed, _ := a.allow("ip:new1", limit, now); !allowed {
+		t.Fatal("expected the first request to the overflow bucket to be allowed")
+	}
+	if allowed, _ := a.allow("ip:new2", limit, now); allowed {
+		t.Fatal("expected the overflow bucket to be shared")
+	}
+	if len(a.buckets) != maxBuckets+1 {
+		t.Fatalf("expected only the overflow bucket to be added, got %d buckets", len(a.buckets))
+	}
+	// existing clients keep their own bucket
+	if allowed, _ := a.allow("ip:0", limit, now.Add(time.Second)); !allowed {
+		t.Fatal("expected an existing bucket to be refilled")
+	}
+
+	// once idle buckets expire new clients get their own bucket again
+	later := now.Add(2 * bucketIdleTimeout)
+	if allowed, _ := a.allow("ip:new3", limit, later); !allowed {
+		t.Fatal("expected a new bucket after idle buckets expired")
+	}
+	if _, ok := a.buckets["ip:new3"]; !ok || len(a.buckets) != 1 {
+		t.Fatalf("expected idle buckets to be swept, got %d buckets", len(a.buckets))
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies int
+		forwardedFor   []string
+		expected       string
+	}{
+		{"no proxies", 0, nil, "10.0.0.1"},
+		{"header ignored without proxies", 0, []string{"6.6.6.6"}, "10.0.0.1"},
+		{"one proxy", 1, []string{"2.2.2.2"}, "2.2.2.2"},
+		{"spoofed by the client", 1, []string{"6.6.6.6, 2.2.2.2"}, "2.2.2.2"},
+		{"several spoofed by the client", 1, []string{"6.6.6.6, 7.7.7.7, 2.2.2.2"}, "2.2.2.2"},
+		{"two proxies", 2, []string{"6.6.6.6, 2.2.2.2, 3.3.3.3"}, "2.2.2.2"},
+		{"several headers", 2, []string{"6.6.6.6, 2.2.2.2", "3.3.3.3"}, "2.2.2.2"},
+		{"fewer hops than proxies", 3, []string{"2.2.2.2, 3.3.3.3"}, "2.2.2.2"},
+		{"ipv6", 1, []string{"6.6.6.6, 2001:db8::1"}, "2001:db8::1"},
+		{"not an ip", 1, []string{"2.2.2.2, unknown"}, "10.0.0.1"},
+		{"no header", 1, nil, "10.0.0.1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newAuthenticator(AuthConfig{TrustedProxies: tc.trustedProxies})
+			req := httptest.NewRequest(http.MethodGet, "/v1/chains", nil)
+			req.RemoteAddr = "10.0.0.1:52000"
+			for _, value := range tc.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if ip := a.clientIP(req); ip != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, ip)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	a := newAuthenticator(AuthConfig{
+		Keys:           []APIKey{{Name: "app", Key: "s3cret", Scope: ScopeRead, RateLimit: RateLimit{Rate: 0.5, Burst: 1}}},
+		Anonymous:      RateLimit{Rate: 0.25, Burst: 2},
+		TrustedProxies: 1,
+	})
+	handler := a.middleware(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+	}))
+	serve := func(forwardedFor, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/chains", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	steps := []struct {
+		name         string
+		forwardedFor string
+		key          string
+		status       int
+		retryAfter   string
+	}{
+		{"anonymous", "1.1.1.1", "", http.StatusOK, ""},
+		{"invalid key", "1.1.1.1", "guess", http.StatusUnauthorized, ""},
+		{"invalid keys are throttled", "1.1.1.1", "guess", http.StatusTooManyRequests, "4"},
+		{"anonymous requests share the bucket", "1.1.1.1", "", http.StatusTooManyRequests, "4"},
+		{"spoofing another client", "9.9.9.9, 1.1.1.1", "", http.StatusTooManyRequests, "4"},
+		{"another client", "2.2.2.2", "", http.StatusOK, ""},
+		{"valid key", "1.1.1.1", "s3cret", http.StatusOK, ""},
+		{"valid key rate limit", "1.1.1.1", "s3cret", http.StatusTooManyRequests, "2"},
+	}
+	for _, step := range steps {
+		rec := serve(step.forwardedFor, step.key)
+		if rec.Code != step.status {
+			t.Fatalf("%s: expected status %d, got %d: %s", step.name, step.status, rec.Code, rec.Body)
+		}
+		if got := rec.Header().Get("Retry-After"); got != step.retryAfter {
+			t.Fatalf("%s: expected Retry-After %q, got %q", step.name, step.retryAfter, got)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// Binaries returns the release artifacts of a chain's recommended version.
+// If both "os" and "arch" are provided as query parameters only the matching
+// artifact is returned.
+func (h Handler) Binaries(res http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	chainName, ok := vars["chain"]
+	if !ok {
+		badRequest(res, "missing chain")
+		return
+	}
+
+	exists, chain := h.snapshot().findChain(chainName)
+	if !exists {
+		resourceNotFound(res, "chain %s not found", chainName)
+		return
+	}
+	var binaries types.Binaries
+	if chain.Codebase != nil && chain.Codebase.Binaries != nil {
+		binaries = *chain.Codebase.Binaries
+	}
+
+	query := req.URL.Query()
+	os, arch := query.Get("os"), query.Get("arch")
+	switch {
+	case os == "" && arch == "":
+		respondWithJSON(res, binaries.List())
+	case os == "" || arch == "":
+		badRequest(res, "os and arch must be given together")
+	default:
+		binary, ok := binaries.Binary(os, arch)
+		if !ok {
+			resourceNotFound(res, "no binary for %s/%s", os, arch)
+			return
+		}
+		respondWithJSON(res, binary)
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+func TestBinaries(t *testing.T) {
+	_, _, router := newTestHandler(t, map[string]string{
+		"cosmoshub/chain.json": `{"chain_name":"cosmoshub","chain_id":"cosmoshub-4","codebase":{"binaries":{
+			"linux/amd64":"https://example.com/gaiad-linux-amd64?checksum=sha256:abc",
+			"darwin/arm64":"https://example.com/gaiad-darwin-arm64",
+			"linux/riscv64":"https://example.com/gaiad-linux-riscv64"}}}`,
+		"osmosis/chain.json": `{"chain_name":"osmosis"}`,
+	})
+
+	rec := serveTest(router, http.MethodGet, "/v1/chain/cosmoshub/binaries")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var list []types.Binary
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	expected := []types.Binary{
+		{OS: "darwin", Arch: "arm64", URL: "https://example.com/gaiad-darwin-arm64"},
+		{OS: "linux", Arch: "amd64", URL: "https://example.com/gaiad-linux-amd64", Checksum: "sha256:abc"},
+		{OS: "linux", Arch: "riscv64", URL: "https://example.com/gaiad-linux-riscv64"},
+	}
+	if !reflect.DeepEqual(list, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, list)
+	}
+
+	testCases := []struct {
+		name   string
+		target string
+		status int
+		url    string
+	}{
+		{"platform", "/v1/chain/cosmoshub/binaries?os=linux&arch=amd64", http.StatusOK, "https://example.com/gaiad-linux-amd64"},
+		{"alias", "/v1/chain/cosmoshub/binaries?os=Darwin&arch=aarch64", http.StatusOK, "https://example.com/gaiad-darwin-arm64"},
+		{"platform outside the schema", "/v1/chain/cosmoshub/binaries?os=linux&arch=riscv64", http.StatusOK, "https://example.com/gaiad-linux-riscv64"},
+		{"chain id", "/v1/chain/cosmoshub-4/binaries?os=linux&arch=amd64", http.StatusOK, "https://example.com/gaiad-linux-amd64"},
+		{"unknown platform", "/v1/chain/cosmoshub/binaries?os=plan9&arch=amd64", http.StatusNotFound, ""},
+		{"missing platform", "/v1/chain/cosmoshub/binaries?os=windows&arch=amd64", http.StatusNotFound, ""},
+		{"only os", "/v1/chain/cosmoshub/binaries?os=linux", http.StatusBadRequest, ""},
+		{"only arch", "/v1/chain/cosmoshub/binaries?arch=amd64", http.StatusBadRequest, ""},
+		{"no binaries", "/v1/chain/osmosis/binaries?os=linux&arch=amd64", http.StatusNotFound, ""},
+		{"unknown chain", "/v1/chain/unknown/binaries", http.StatusNotFound, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serveTest(router, http.MethodGet, tc.target)
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, rec.Code, rec.Body)
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+			var binary types.Binary
+			if err := json.Unmarshal(rec.Body.Bytes(), &binary); err != nil {
+				t.Fatal(err)
+			}
+			if binary.URL != tc.url {
+				t.Fatalf("expected %s, got %s", tc.url, binary.URL)
+			}
+		})
+	}
+
+	rec = serveTest(router, http.MethodGet, "/v1/chain/osmosis/binaries")
+	if rec.Code != http.StatusOK || rec.Body.String() != "[]" {
+		t.Fatalf("expected an empty list, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// Bootstrap returns everything needed to stand up a node for a chain. The
+// "format" query parameter selects how the bundle is rendered: "json" (default),
+// "sh" for a setup script or "config.toml" / "app.toml" for config fragments.
+// The "os" and "arch" query parameters select the binary (default linux/amd64).
+func (h Handler) Bootstrap(res http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	chainName, ok := vars["chain"]
+	if !ok {
+		badRequest(res, "missing chain")
+		return
+	}
+
+	exists, chain := h.snapshot().findChain(chainName)
+	if !exists {
+		resourceNotFound(res, "chain %s not found", chainName)
+		return
+	}
+	query := req.URL.Query()
+	goos, arch := query.Get("os"), query.Get("arch")
+	if goos == "" {
+		goos = "linux"
+	}
+	if arch == "" {
+		arch = "amd64"
+	}
+	bundle := newBootstrap(chain, goos, arch)
+
+	var tmpl *template.Template
+	switch query.Get("format") {
+	case "", "json":
+		respondWithJSON(res, bundle)
+		return
+	case "sh":
+		// the script can't guess where to install the node
+		if bundle.DaemonName == "" || bundle.NodeHome == "" {
+			respondWithError(res, http.StatusUnprocessableEntity, types.ErrCodeValidation,
+				fmt.Sprintf("chain %s has no daemon_name or node_home, which the setup script requires", chain.ChainName), nil)
+			return
+		}
+		tmpl = scriptTemplate
+	case "config.toml":
+		tmpl = configTemplate
+	case "app.toml":
+		tmpl = appTemplate
+	default:
+		badRequest(res, "unknown format %q, expected json, sh, config.toml or app.toml", query.Get("format"))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, bundle); err != nil {
+		h.requestLog(req).Error("rendering bootstrap", "chain", chain.ChainName, "err", err)
+		internalError(res)
+		return
+	}
+	respondWithText(res, buf.Bytes())
+}
+
+func newBootstrap(chain types.Chain, goos, arch string) types.Bootstrap {
+	bundle := types.Bootstrap{
+		ChainName: chain.ChainName,
+		ChainID:   chain.ChainID,
+	}
+	if chain.DaemonName != nil {
+		bundle.DaemonName = *chain.DaemonName
+	}
+	if chain.NodeHome != nil {
+		bundle.NodeHome = *chain.NodeHome
+	}
+	if chain.Codebase != nil {
+		bundle.GitRepo = chain.Codebase.GitRepo
+		bundle.RecommendedVersion = chain.Codebase.RecommendedVersion
+		if chain.Codebase.Binaries != nil {
+			if binary, ok := chain.Codebase.Binaries.Binary(goos, arch); ok {
+				bundle.BinaryURL = binary.URL
+				bundle.BinaryChecksum = binary.Checksum
+			}
+		}
+	}
+	if chain.Genesis != nil && chain.Genesis.GenesisURL != nil {
+		bundle.GenesisURL = *chain.Genesis.GenesisURL
+	}
+	if chain.Peers != nil {
+		bundle.Seeds = formatPeers(chain.Peers.Seeds)
+		bundle.PersistentPeers = formatPeers(chain.Peers.PersistentPeers)
+	}
+	if chain.Fees != nil {
+		bundle.MinimumGasPrices = formatGasPrices(chain.Fees.FeeTokens)
+	}
+	return bundle
+}
+
+// formatPeers joins peers in the "id@address" form used by config.toml
+func formatPeers(peers []types.PersistentPeerElement) string {
+	entries := make([]string, len(peers))
+	for idx, peer := range peers {
+		entries[idx] = peer.ID + "@" + peer.Address
+	}
+	return strings.Join(entries, ",")
+}
+
+// formatGasPrices joins fee tokens in the "0.01uatom" form used by app.toml.
+// The fixed minimum gas price is used, falling back to the low gas price.
+// Tokens without either are skipped.
+func formatGasPrices(tokens []types.FeeTokenElement) string {
+	entries := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		price := token.FixedMinGasPrice
+		if price == nil {
+			price = token.LowGasPrice
+		}
+		if price == nil {
+			continue
+		}
+		entries = append(entries, strconv.FormatFloat(*price, 'f', -1, 64)+token.Denom)
+	}
+	return strings.Join(entries, ",")
+}
+
+// shellQuote wraps a value in single quotes so that it is safe to embed in a
+// shell script regardless of its contents
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// shellHome quotes a node home directory for a shell script, only expanding a
+// leading "$HOME" or "~"
+func shellHome(home string) string {
+	for _, prefix := range []string{"$HOME", "~"} {
+		if strings.HasPrefix(home, prefix) {
+			return `"$HOME"` + shellQuote(strings.TrimPrefix(home, prefix))
+		}
+	}
+	return shellQuote(home)
+}
+
+// tomlQuote renders a value as a TOML basic string
+func tomlQuote(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\b':
+			b.WriteString(`\b`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// urlPath returns the lower cased path of a url, without its query
+func urlPath(rawURL string) string {
+	path := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		path = u.Path
+	}
+	return strings.ToLower(path)
+}
+
+// archiveType returns the archive format of a binary url, judged by the
+// extension of its path, or an empty string if it is a plain binary
+func archiveType(binaryURL string) string {
+	path := urlPath(binaryURL)
+	switch {
+	case strings.HasSuffix(path, ".zip"):
+		return "zip"
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"),
+		strings.HasSuffix(path, ".tar.xz"), strings.HasSuffix(path, ".tar.bz2"), strings.HasSuffix(path, ".tar"):
+		return "tar"
+	default:
+		return ""
+	}
+}
+
+// genesisType returns how a genesis url is packed, judged by the extension of
+// its path: "tgz", "tar", "gz" or an empty string for a plain JSON file.
+// Archives are expected to contain only the genesis file.
+func genesisType(genesisURL string) string {
+	path := urlPath(genesisURL)
+	switch {
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		return "tgz"
+	case strings.HasSuffix(path, ".tar"):
+		return "tar"
+	case strings.HasSuffix(path, ".gz"):
+		return "gz"
+	default:
+		return ""
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"shell":      shellQuote,
+	"home":       shellHome,
+	"hasPrefix":  strings.HasPrefix,
+	"trimPrefix": strings.TrimPrefix,
+	"toml":       tomlQuote,
+	"archive":    archiveType,
+	"genesis":    genesisType,
+}
+
+var configTemplate = template.Must(template.New("config.toml").Funcs(templateFuncs).Parse(
+	`# config.toml fragment generated by skychart
+[p2p]
+seeds = {{ toml .Seeds }}
+persistent_peers = {{ toml .PersistentPeers }}
+`))
+
+var appTemplate = template.Must(template.New("app.toml").Funcs(templateFuncs).Parse(
+	`# app.toml fragment generated by skychart
+minimum-gas-prices = {{ toml .MinimumGasPrices }}
+`))
+
+var scriptTemplate = template.Must(template.New("bootstrap.sh").Funcs(templateFuncs).Parse(
+	`#!/bin/sh
+# Bootstrap script generated by skychart. Values from the registry are only
+# ever assigned to variables, quoted, so they can't inject commands.
+set -eu
+
+CHAIN_ID={{ shell .ChainID }}
+DAEMON={{ shell .DaemonName }}
+if [ -z "${NODE_HOME:-}" ]; then
+	NODE_HOME={{ home .NodeHome }}
+fi
+MONIKER="${MONIKER:-skychart}"
+{{ if .BinaryURL }}
+# Install the recommended version of the daemon
+VERSION={{ shell .RecommendedVersion }}
+echo "Installing $DAEMON $VERSION" >&2
+DOWNLOAD="$(mktemp)"
+curl -fsSL -o "$DOWNLOAD" {{ shell .BinaryURL }}
+{{- if hasPrefix .BinaryChecksum "sha256:" }}
+echo {{ shell (trimPrefix .BinaryChecksum "sha256:") }}"  $DOWNLOAD" | sha256sum -c -
+{{- end }}
+{{- with archive .BinaryURL }}
+# the release is an archive containing the daemon
+EXTRACT_DIR="$(mktemp -d)"
+{{ if eq . "zip" }}unzip -q "$DOWNLOAD" -d "$EXTRACT_DIR"{{ else }}tar -xf "$DOWNLOAD" -C "$EXTRACT_DIR"{{ end }}
+BINARY="$(find "$EXTRACT_DIR" -type f -name "$DAEMON" | head -n 1)"
+if [ -z "$BINARY" ]; then
+	echo "$DAEMON not found in the release archive" >&2
+	exit 1
+fi
+mv "$BINARY" "./$DAEMON"
+{{- else }}
+mv "$DOWNLOAD" "./$DAEMON"
+{{- end }}
+chmod +x "./$DAEMON"
+export PATH="$PWD:$PATH"
+{{ end }}
+"$DAEMON" init "$MONIKER" --chain-id "$CHAIN_ID" --home "$NODE_HOME"
+{{ if .GenesisURL }}
+GENESIS_URL={{ shell .GenesisURL }}
+{{- with genesis .GenesisURL }}
+{{ if eq . "tgz" }}curl -fsSL "$GENESIS_URL" | tar -xzO > "$NODE_HOME/config/genesis.json"
+{{- else if eq . "tar" }}curl -fsSL "$GENESIS_URL" | tar -xO > "$NODE_HOME/config/genesis.json"
+{{- else }}curl -fsSL "$GENESIS_URL" | gunzip > "$NODE_HOME/config/genesis.json"
+{{- end }}
+{{- else }}
+curl -fsSL -o "$NODE_HOME/config/genesis.json" "$GENESIS_URL"
+{{- end }}
+{{ end }}
+# escapes a value for the replacement of a sed substitution
+sed_escape() {
+	printf '%s\n' "$1" | sed -e 's/[|&\\]/\\&/g'
+}
+# the values are TOML strings, quotes included
+SEEDS="$(sed_escape {{ shell (toml .Seeds) }})"
+PERSISTENT_PEERS="$(sed_escape {{ shell (toml .PersistentPeers) }})"
+MINIMUM_GAS_PRICES="$(sed_escape {{ shell (toml .MinimumGasPrices) }})"
+sed -i.bak \
+	-e "s|^seeds *=.*|seeds = $SEEDS|" \
+	-e "s|^persistent_peers *=.*|persistent_peers = $PERSISTENT_PEERS|" \
+	"$NODE_HOME/config/config.toml"
+sed -i.bak \
+	-e "s|^minimum-gas-prices *=.*|minimum-gas-prices = $MINIMUM_GAS_PRICES|" \
+	"$NODE_HOME/config/app.toml"
+`))
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+func TestGenesisType(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://example.com/genesis.json", ""},
+		{"https://example.com/genesis.json.gz", "gz"},
+		{"https://example.com/genesis.tar.gz", "tgz"},
+		{"https://example.com/genesis.TGZ", "tgz"},
+		{"https://example.com/genesis.tar", "tar"},
+		{"https://example.com/genesis.tar.gz?download=1", "tgz"},
+		{"https://example.com/genesis.json?name=genesis.gz", ""},
+	}
+	for _, tc := range testCases {
+		if got := genesisType(tc.url); got != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.url, tc.expected, got)
+		}
+	}
+}
+
+// fakeDaemon is a daemon whose init writes the config files that the
+// bootstrap script edits
+const fakeDaemon = `#!/bin/sh
+while [ $# -gt 0 ]; do
+	if [ "$1" = "--home" ]; then home="$2"; fi
+	shift
+done
+mkdir -p "$home/config"
+printf 'seeds = ""\npersistent_peers = ""\n' > "$home/config/config.toml"
+printf 'minimum-gas-prices = ""\n' > "$home/config/app.toml"
+`
+
+// TestBootstrapInjection renders a chain whose values try to break out of
+// the script and the config fragments with newlines and sed metacharacters
+func TestBootstrapInjection(t *testing.T) {
+	root := t.TempDir()
+	pwned := filepath.Join(root, "pwned")
+	inject := "\ntouch " + pwned + "\ninjected = true\n"
+
+	daemon := filepath.Join(root, "release", "simd")
+	if err := os.MkdirAll(filepath.Dir(daemon), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(daemon, []byte(fakeDaemon), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(fakeDaemon))
+	binaryURL := "file://" + daemon + "?checksum=sha256:" + hex.EncodeToString(sum[:])
+	genesisPath := filepath.Join(root, "release", "genesis.tar.gz")
+	if err := os.WriteFile(genesisPath, gzipped(t, tarred(t, "genesis.json", []byte(testGenesis))), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	genesisURL := "file://" + genesisPath
+
+	daemonName, nodeHome, gasPrice := "simd", "$HOME/.simd", 0.01
+	chain := types.Chain{
+		ChainName:  "cosmoshub",
+		ChainID:    "cosmoshub-4" + inject,
+		DaemonName: &daemonName,
+		NodeHome:   &nodeHome,
+		Codebase: &types.Codebase{
+			RecommendedVersion: "v1" + inject,
+			Binaries:           &types.Binaries{LinuxAmd64: &binaryURL},
+		},
+		Genesis: &types.Genesis{GenesisURL: &genesisURL},
+		Peers: &types.Peers{
+			Seeds:           []types.PersistentPeerElement{{ID: "abc", Address: `1.2.3.4:26656|&\"` + inject}},
+			PersistentPeers: []types.PersistentPeerElement{{ID: "def", Address: "5.6.7.8:26656"}},
+		},
+		Fees: &types.Fees{FeeTokens: []types.FeeTokenElement{{Denom: "uatom" + inject, LowGasPrice: &gasPrice}}},
+	}
+	bundle := newBootstrap(chain, "linux", "amd64")
+
+	for _, tmpl := range []*template.Template{configTemplate, appTemplate} {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, bundle); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if strings.HasPrefix(line, "injected") || strings.HasPrefix(line, "touch") {
+				t.Errorf("%s: value escaped onto its own line:\n%s", tmpl.Name(), buf.String())
+			}
+		}
+	}
+
+	for _, tool := range []string{"sh", "curl", "sed", "tar", "sha256sum"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not installed", tool)
+		}
+	}
+	var script bytes.Buffer
+	if err := scriptTemplate.Execute(&script, bundle); err != nil {
+		t.Fatal(err)
+	}
+	home := filepath.Join(root, "home")
+	cmd := exec.Command("sh", "-s")
+	cmd.Dir = root
+	cmd.Stdin = &script
+	cmd.Env = append(os.Environ(), "NODE_HOME="+home)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("running the script: %v: %s\n%s", err, out, script.String())
+	}
+
+	if _, err := os.Stat(pwned); err == nil {
+		t.Fatalf("the script ran an injected command:\n%s", script.String())
+	}
+	expected := map[string]string{
+		"config.toml":  "seeds = " + tomlQuote(bundle.Seeds) + "\npersistent_peers = " + tomlQuote(bundle.PersistentPeers) + "\n",
+		"app.toml":     "minimum-gas-prices = " + tomlQuote(bundle.MinimumGasPrices) + "\n",
+		"genesis.json": testGenesis,
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(home, "config", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("%s: expected\n%s\ngot\n%s", name, content, data)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// etag identifies a resource of the snapshot. It is derived from the content
+// of the snapshot, so it only changes when the registry does and is the same
+// across restarts and replicas.
+func (r *registry) etag(resource string) string {
+	sum := sha256.Sum256([]byte(r.version + "\x00" + resource))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// fingerprint hashes the content that responses are derived from
+func (r *registry) fingerprint() (string, error) {
+	hash := sha256.New()
+	enc := json.NewEncoder(hash)
+	for _, name := range sortedKeys(r.chainList) {
+		if err := enc.Encode([]interface{}{"chain", name, r.chainList[name]}); err != nil {
+			return "", err
+		}
+	}
+	for _, name := range sortedKeys(r.assetList) {
+		if err := enc.Encode([]interface{}{"assetlist", name, r.assetList[name]}); err != nil {
+			return "", err
+		}
+	}
+	for _, name := range sortedKeys(r.provenance) {
+		if err := enc.Encode([]interface{}{"provenance", name, r.provenance[name]}); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// cacheSnapshot sets the ETag, Last-Modified and Cache-Control headers of
+// responses derived from the registry snapshot and responds with 304 Not
+// Modified if the client's copy is still current. The preconditions are only
+// evaluated once the handler has found the resource. Error responses are not
+// cached.
+func (h Handler) cacheSnapshot(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		reg := h.snapshot()
+		if (req.Method != http.MethodGet && req.Method != http.MethodHead) || reg.modified.IsZero() {
+			res.Header().Set("Cache-Control", "no-cache")
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		// the resource includes the query in a canonical order. API keys
+		// don't change the response.
+		resource := req.URL.Path
+		if req.URL.RawQuery != "" {
+			query := req.URL.Query()
+			query.Del("api_key")
+			if len(query) > 0 {
+				resource += "?" + query.Encode()
+			}
+		}
+		etag := reg.etag(resource)
+		header := res.Header()
+		header.Set("ETag", etag)
+		header.Set("Last-Modified", reg.modified.UTC().Format(http.TimeFormat))
+		header.Set("Cache-Control", h.cacheControl)
+		addVary(header, "Accept-Encoding")
+
+		next.ServeHTTP(&conditionalWriter{ResponseWriter: res, req: req, etag: etag, modified: reg.modified}, req)
+	})
+}
+
+// notModified evaluates the If-None-Match and If-Modified-Since headers of a
+// request for a resource that exists. If-Modified-Since is ignored if
+// If-None-Match is present. The ETag of any encoding of the resource matches.
+func notModified(req *http.Request, etag string, modified time.Time) bool {
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+			for _, encoding := range encodings {
+				if candidate == encodedETag(etag, encoding) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// conditionalWriter answers a successful response with 304 Not Modified if
+// the client's copy is current, discarding the body, and removes the caching
+// headers from error responses
+type conditionalWriter struct {
+	http.ResponseWriter
+	req         *http.Request
+	etag        string
+	modified    time.Time
+	wroteHeader bool
+	discard     bool
+}
+
+func (w *conditionalWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.wroteHeader = true
+	header := w.Header()
+	switch {
+	case status == http.StatusOK && notModified(w.req, w.etag, w.modified):
+		if header.Get("Content-Encoding") == "" {
+			if encoding := negotiateEncoding(w.req); encoding != "" {
+				header.Set("ETag", encodedETag(w.etag, encoding))
+			}
+		}
+		header.Del("Content-Length")
+		header.Del("Content-Encoding")
+		w.discard = true
+		status = http.StatusNotModified
+	case status >= http.StatusBadRequest:
+		header.Del("ETag")
+		header.Del("Last-Modified")
+		header.Set("Cache-Control", "no-cache")
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *conditionalWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.discard {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// noStore prevents responses from being cached
+func noStore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Cache-Control", "no-store")
+		next.ServeHTTP(res, req)
+	})
+}
+
+// cacheControl returns the Cache-Control header of cacheable responses. If
+// API keys are required, shared caches must not serve responses to clients
+// without one.
+func cacheControl(maxAge time.Duration, requireKey bool) string {
+	visibility := "public"
+	if requireKey {
+		visibility = "private"
+	}
+	return visibility + ", max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCacheSnapshot(t *testing.T) {
+	_, _, router := newTestHandler(t, map[string]string{
+		"cosmoshub/chain.json": `{"chain_name":"cosmoshub","chain_id":"cosmoshub-4"}`,
+	})
+	etag := serveTest(router, http.MethodGet, "/v1/chain/cosmoshub").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	past := time.Unix(0, 0).UTC().Format(http.TimeFormat)
+
+	testCases := []struct {
+		name    string
+		method  string
+		path    string
+		headers []string
+		status  int
+	}{
+		{"no preconditions", http.MethodGet, "/v1/chain/cosmoshub", nil, http.StatusOK},
+		{"head", http.MethodHead, "/v1/chains", nil, http.StatusOK},
+		{"matching etag", http.MethodGet, "/v1/chain/cosmoshub", []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"weak etag", http.MethodGet, "/v1/chain/cosmoshub", []string{"If-None-Match", "W/" + etag}, http.StatusNotModified},
+		{"encoded etag", http.MethodGet, "/v1/chain/cosmoshub", []string{"If-None-Match", encodedETag(etag, "gzip")}, http.StatusNotModified},
+		{"other etag", http.MethodGet, "/v1/chain/cosmoshub", []string{"If-None-Match", `"other"`}, http.StatusOK},
+		{"any etag", http.MethodGet, "/v1/chain/cosmoshub", []string{"If-None-Match", "*"}, http.StatusNotModified},
+		{"head matching etag", http.MethodHead, "/v1/chain/cosmoshub", []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"any etag of a missing chain", http.MethodGet, "/v1/chain/doesnotexist", []string{"If-None-Match", "*"}, http.StatusNotFound},
+		{"modified since", http.MethodGet, "/v1/chain/cosmoshub", []string{"If-Modified-Since", past}, http.StatusOK},
+		{"not modified since", http.MethodGet, "/v1/chain/cosmoshub", []string{"If-Modified-Since", future}, http.StatusNotModified},
+		{"not modified since of a missing chain", http.MethodGet, "/v1/chain/doesnotexist", []string{"If-Modified-Since", future}, http.StatusNotFound},
+		{"etag takes precedence", http.MethodGet, "/v1/chain/cosmoshub", []string{"If-None-Match", `"other"`, "If-Modified-Since", future}, http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serveTest(router, tc.method, tc.path, tc.headers...)
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, rec.Code, rec.Body)
+			}
+			switch rec.Code {
+			case http.StatusNotModified:
+				if rec.Body.Len() != 0 {
+					t.Fatalf("expected no body, got %s", rec.Body)
+				}
+			case http.StatusNotFound:
+				if rec.Header().Get("ETag") != "" {
+					t.Fatal("error responses must not have an ETag")
+				}
+			}
+		})
+	}
+}
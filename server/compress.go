@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// brotliLevel is the quality of pre-rendered brotli payloads. Higher
+// qualities barely shrink JSON payloads and the two highest are around a
+// hundred times slower to render.
+const brotliLevel = brotli.DefaultCompression
+
+// encodings are the content codings that responses can be compressed with,
+// in order of preference
+var encodings = []string{"br", "gzip"}
+
+// payload is a response rendered once per snapshot in every supported
+// encoding
+type payload struct {
+	json []byte
+	gzip []byte
+	br   []byte
+}
+
+func newPayload(v interface{}) (*payload, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var gz bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	_, _ = zw.Write(data)
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	var br bytes.Buffer
+	bw := brotli.NewWriterOptions(&br, brotli.WriterOptions{Quality: brotliLevel, LGWin: brotliWindow(len(data))})
+	_, _ = bw.Write(data)
+	if err := bw.Close(); err != nil {
+		return nil, err
+	}
+	return &payload{json: data, gzip: gz.Bytes(), br: br.Bytes()}, nil
+}
+
+// brotliWindow returns the smallest brotli window, as a power of two, that
+// fits data of size n. The default window is much larger than most payloads
+// and allocating it dominates the cost of rendering them.
+func brotliWindow(n int) int {
+	lgwin := 10
+	for lgwin < 24 && 1<<lgwin < n {
+		lgwin++
+	}
+	return lgwin
+}
+
+// encoded returns the payload in an encoding
+func (p *payload) encoded(encoding string) []byte {
+	switch encoding {
+	case "br":
+		return p.br
+	case "gzip":
+		return p.gzip
+	default:
+		return p.json
+	}
+}
+
+// respondWithPayload writes a pre-rendered payload in the best encoding the
+// client accepts. It falls back to rendering v if there is no payload.
+func respondWithPayload(w http.ResponseWriter, req *http.Request, p *payload, v interface{}) {
+	if p == nil {
+		respondWithJSON(w, v)
+		return
+	}
+	header := w.Header()
+	addVary(header, "Accept-Encoding")
+	header.Set("Content-Type", "application/json")
+	encoding := negotiateEncoding(req)
+	body := p.encoded(encoding)
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+		if etag := header.Get("ETag"); etag != "" {
+			header.Set("ETag", encodedETag(etag, encoding))
+		}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// negotiateEncoding returns the supported encoding with the highest quality
+// value in the Accept-Encoding header of the request, or an empty string if
+// the response should not be compressed. Ties go to the smaller encoding.
+// Compression is preferred over identity unless identity is given a higher
+// quality.
+func negotiateEncoding(req *http.Request) string {
+	accepted := req.Header.Values("Accept-Encoding")
+	if len(accepted) == 0 {
+		return ""
+	}
+	weights := make(map[string]float64)
+	for _, value := range accepted {
+		for _, coding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "x-gzip" {
+				name = "gzip"
+			}
+			weight := 1.0
+			for _, param := range strings.Split(params, ";") {
+				if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+					parsed, err := strconv.ParseFloat(q, 64)
+					if err != nil || parsed < 0 || parsed > 1 {
+						parsed = 0
+					}
+					weight = parsed
+				}
+			}
+			if name != "" {
+				weights[name] = weight
+			}
+		}
+	}
+	weightOf := func(encoding string) (float64, bool) {
+		if weight, ok := weights[encoding]; ok {
+			return weight, true
+		}
+		weight, ok := weights["*"]
+		return weight, ok
+	}
+
+	best, bestWeight := "", 0.0
+	for _, encoding := range encodings {
+		if weight, _ := weightOf(encoding); weight > bestWeight {
+			best, bestWeight = encoding, weight
+		}
+	}
+	if identity, ok := weightOf("identity"); ok && identity > bestWeight {
+		return ""
+	}
+	return best
+}
+
+// encodedETag returns the ETag of an encoding of a response
+func encodedETag(etag, encoding string) string {
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// encoder is a compressing writer that can be reused
+type encoder interface {
+	io.WriteCloser
+	Reset(io.Writer)
+}
+
+var encoders = map[string]*sync.Pool{
+	"gzip": {New: func() interface{} { return gzip.NewWriter(nil) }},
+	"br":   {New: func() interface{} { return brotli.NewWriter(nil) }},
+}
+
+// compress compresses JSON and text responses that weren't pre-rendered in
+// the best encoding the client accepts. Responses with a Content-Length or
+// Content-Encoding, such as genesis files and pre-rendered payloads, are left
+// as they are.
+func compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		encoding := negotiateEncoding(req)
+		if encoding == "" {
+			next.ServeHTTP(res, req)
+			return
+		}
+		w := &compressResponseWriter{ResponseWriter: res, encoding: encoding}
+		defer w.close()
+		next.ServeHTTP(w, req)
+	})
+}
+
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	zw          encoder
+	wroteHeader bool
+}
+
+func (w *compressResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	header := w.Header()
+	if status == http.StatusOK && header.Get("Content-Encoding") == "" && header.Get("Content-Length") == "" && compressible(header.Get("Content-Type")) {
+		addVary(header, "Accept-Encoding")
+		header.Set("Content-Encoding", w.encoding)
+		if etag := header.Get("ETag"); etag != "" {
+			header.Set("ETag", encodedETag(etag, w.encoding))
+		}
+		w.zw = encoders[w.encoding].Get().(encoder)
+		w.zw.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressResponseWriter) close() {
+	if w.zw != nil {
+		_ = w.zw.Close()
+		encoders[w.encoding].Put(w.zw)
+	}
+}
+
+// addVary adds a header to Vary unless it is already listed
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", name)
+}
+
+func compressible(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "text/")
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	testCases := []struct {
+		accept   string
+		encoding string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"x-gzip", "gzip"},
+		{"br", "br"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"br;q=1.0, gzip;q=0.8", "br"},
+		{"gzip;q=0, br;q=0", ""},
+		{"*", "br"},
+		{"*;q=0.5, br;q=0", "gzip"},
+		{"gzip;q=0.5, identity", ""},
+		{"gzip;q=0.5, identity;q=0.5", "gzip"},
+		{"gzip;q=invalid", ""},
+		{"deflate", ""},
+		{"GZIP;Q=1", "gzip"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept-Encoding", tc.accept)
+			}
+			if encoding := negotiateEncoding(req); encoding != tc.encoding {
+				t.Fatalf("expected %q, got %q", tc.encoding, encoding)
+			}
+		})
+	}
+}
+
+func TestRespondWithPayload(t *testing.T) {
+	v := benchmarkAssetList(10)
+	p, err := newPayload(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"":     func(r io.Reader) (io.Reader, error) { return r, nil },
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	}
+	for encoding, decode := range decoders {
+		for name, respond := range map[string]http.Handler{
+			"rendered": http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("ETag", `"abc"`)
+				respondWithPayload(w, req, p, v)
+			}),
+			"on the fly": compress(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("ETag", `"abc"`)
+				respondWithJSON(w, v)
+			})),
+		} {
+			t.Run(name+"/"+encoding, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Accept-Encoding", encoding)
+				rec := httptest.NewRecorder()
+				respond.ServeHTTP(rec, req)
+
+				if got := rec.Header().Get("Content-Encoding"); got != encoding {
+					t.Fatalf("expected Content-Encoding %q, got %q", encoding, got)
+				}
+				etag := `"abc"`
+				if encoding != "" {
+					etag = encodedETag(etag, encoding)
+				}
+				if got := rec.Header().Get("ETag"); got != etag {
+					t.Fatalf("expected ETag %s, got %s", etag, got)
+				}
+				r, err := decode(rec.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, err := io.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(bytes.TrimSpace(body), p.json) {
+					t.Fatalf("unexpected body %s", body)
+				}
+			})
+		}
+	}
+}
+
+// benchmarkAssetList returns an asset list with the given number of assets,
+// similar in shape to those in the registry
+func benchmarkAssetList(assets int) types.AssetList {
+	description := "The native staking and governance token of the chain"
+	coingecko := "cosmos"
+	list := types.AssetList{ChainID: "cosmoshub-4"}
+	for idx := 0; idx < assets; idx++ {
+		base := fmt.Sprintf("ibc/%064X", idx)
+		display := fmt.Sprintf("token%d", idx)
+		name, symbol := fmt.Sprintf("Token %d", idx), fmt.Sprintf("TKN%d", idx)
+		list.Assets = append(list.Assets, types.AssetElement{
+			Base:        base,
+			Display:     display,
+			Name:        &name,
+			Symbol:      &symbol,
+			Description: &description,
+			CoingeckoID: &coingecko,
+			DenomUnits: []types.DenomUnitElement{
+				{Denom: base, Exponent: 0},
+				{Denom: display, Exponent: 6},
+			},
+			Traces: []types.Trace{{
+				Type:         types.TraceTypeIbc,
+				Counterparty: types.Counterparty{ChainName: "osmosis", BaseDenom: fmt.Sprintf("u%s", display)},
+			}},
+		})
+	}
+	return list
+}
+
+// BenchmarkRespond compares serving a pre-rendered payload with rendering
+// and compressing the response on every request, as before payloads were
+// rendered per snapshot
+func BenchmarkRespond(b *testing.B) {
+	for _, assets := range []int{10, 500} {
+		v := benchmarkAssetList(assets)
+		p, err := newPayload(v)
+		if err != nil {
+			b.Fatal(err)
+		}
+		rendered := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			respondWithPayload(w, req, p, v)
+		})
+		onTheFly := compress(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			respondWithJSON(w, v)
+		}))
+		for _, encoding := range []string{"identity", "gzip", "br"} {
+			req := httptest.NewRequest(http.MethodGet, "/v1/chain/cosmoshub/assets", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			for _, bench := range []struct {
+				name    string
+				handler http.Handler
+			}{{"rendered", rendered}, {"on-the-fly", onTheFly}} {
+				handler := bench.handler
+				b.Run(fmt.Sprintf("assets=%d/%s/%s", assets, encoding, bench.name), func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						handler.ServeHTTP(httptest.NewRecorder(), req)
+					}
+				})
+			}
+		}
+	}
+}
+
+// BenchmarkNewPayload measures the cost of rendering a payload in every
+// encoding, which is paid once per snapshot
+func BenchmarkNewPayload(b *testing.B) {
+	for _, assets := range []int{10, 500} {
+		v := benchmarkAssetList(assets)
+		b.Run(fmt.Sprintf("assets=%d", assets), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := newPayload(v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
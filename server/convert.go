@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// Convert converts an amount between two denom units of the same asset i.e.
+// `/v1/convert?amount=1500000&from=uatom&to=atom`. The optional "chain" query
+// parameter restricts which chain's asset list is used to resolve the denoms.
+func (h Handler) Convert(res http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	amount, from, to := query.Get("amount"), query.Get("from"), query.Get("to")
+	if amount == "" || from == "" || to == "" {
+		badRequest(res, "the amount, from and to parameters are required")
+		return
+	}
+	if _, err := types.ParseDecimal(amount); err != nil {
+		badRequest(res, "invalid amount %q", amount)
+		return
+	}
+
+	asset, ok := h.snapshot().findAssetByDenom(from, query.Get("chain"))
+	if !ok {
+		resourceNotFound(res, "denom %s not found", from)
+		return
+	}
+	if _, ok := asset.DenomUnit(to); !ok {
+		resourceNotFound(res, "denom %s is not a unit of %s", to, asset.Display)
+		return
+	}
+
+	converted, err := asset.Convert(amount, from, to)
+	if err != nil {
+		badRequest(res, "%v", err)
+		return
+	}
+	respondWithJSON(res, types.Amount{Amount: converted, Denom: to})
+}
+
+// findAssetByDenom finds the asset that has a denom unit matching denom. Exact
+// denom matches are preferred over aliases and assets native to a chain over
+// those that have been transferred to it. If chainName is not empty, only
+// that chain's assets are considered.
+func (r *registry) findAssetByDenom(denom, chainName string) (types.AssetElement, bool) {
+	chains := make([]string, 0, len(r.assetList))
+	if chainName != "" {
+		if name, ok := r.chainById[chainName]; ok {
+			chainName = name
+		}
+		chains = append(chains, chainName)
+	} else {
+		for chain := range r.assetList {
+			chains = append(chains, chain)
+		}
+		sort.Strings(chains)
+	}
+
+	var (
+		best      types.AssetElement
+		bestScore = -1
+	)
+	for _, chain := range chains {
+		for _, asset := range r.assetList[chain].Assets {
+			unit, ok := asset.DenomUnit(denom)
+			if !ok {
+				continue
+			}
+			score := 0
+			if unit.Denom == denom {
+				score += 2
+			}
+			if len(asset.Traces) == 0 && asset.Ibc == nil {
+				score++
+			}
+			if score > bestScore {
+				best, bestScore = asset, score
+			}
+		}
+	}
+	return best, bestScore >= 0
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures which cross-origin requests browsers are allowed to
+// make. Empty fields use the defaults.
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed to make requests, i.e.
+	// "https://example.com". "*" allows any origin. Defaults to "*".
+	AllowedOrigins []string
+	// AllowedMethods defaults to GET, HEAD, PUT, PATCH and DELETE
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed in cross-origin requests
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers readable by the browser
+	ExposedHeaders []string
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+var (
+	defaultCORSOrigins = []string{"*"}
+	defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCORSHeaders = []string{"Origin", "Accept", "Content-Type", "Authorization", "X-Requested-With", APIKeyHeader, RequestIDHeader}
+	defaultCORSExposed = []string{RequestIDHeader, "Retry-After"}
+	defaultCORSMaxAge  = 10 * time.Minute
+)
+
+// cors adds CORS headers to every response and answers preflight requests
+type cors struct {
+	anyOrigin bool
+	origins   map[string]bool
+	methods   map[string]bool
+	headers   map[string]bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+func newCORS(cfg CORSConfig) *cors {
+	if len(cfg.AllowedOrigins) == 0 {
+		cfg.AllowedOrigins = defaultCORSOrigins
+	}
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = defaultCORSMethods
+	}
+	if len(cfg.AllowedHeaders) == 0 {
+		cfg.AllowedHeaders = defaultCORSHeaders
+	}
+	if len(cfg.ExposedHeaders) == 0 {
+		cfg.ExposedHeaders = defaultCORSExposed
+	}
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = defaultCORSMaxAge
+	}
+
+	c := &cors{
+		origins:       make(map[string]bool),
+		methods:       make(map[string]bool),
+		headers:       make(map[string]bool),
+		exposeHeaders: strings.Join(cfg.ExposedHeaders, ", "),
+		maxAge:        strconv.Itoa(int(cfg.MaxAge.Seconds())),
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+		}
+		c.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	methods := make([]string, 0, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		method = strings.ToUpper(method)
+		c.methods[method] = true
+		methods = append(methods, method)
+	}
+	c.allowMethods = strings.Join(methods, ", ")
+	headers := make([]string, 0, len(cfg.AllowedHeaders))
+	for _, header := range cfg.AllowedHeaders {
+		header = http.CanonicalHeaderKey(header)
+		c.headers[header] = true
+		headers = append(headers, header)
+	}
+	c.allowHeaders = strings.Join(headers, ", ")
+	return c
+}
+
+// middleware applies the CORS policy. Requests from origins that aren't
+// allowed are still served but without CORS headers, so browsers block them.
+func (c *cors) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		header := res.Header()
+		// unless any origin is allowed, the response depends on the origin so
+		// caches must key on it
+		if !c.anyOrigin {
+			addVary(header, "Origin")
+		}
+
+		origin := req.Header.Get("Origin")
+		preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+		if origin == "" || !c.allowOrigin(origin) {
+			if preflight {
+				res.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		if c.anyOrigin {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if !preflight {
+			header.Set("Access-Control-Expose-Headers", c.exposeHeaders)
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		addVary(header, "Access-Control-Request-Method")
+		addVary(header, "Access-Control-Request-Headers")
+		if c.allowPreflight(req) {
+			header.Set("Access-Control-Allow-Methods", c.allowMethods)
+			header.Set("Access-Control-Allow-Headers", c.allowHeaders)
+			header.Set("Access-Control-Max-Age", c.maxAge)
+		}
+		res.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (c *cors) allowOrigin(origin string) bool {
+	return c.anyOrigin || c.origins[origin]
+}
+
+// allowPreflight reports whether the method and headers requested by a
+// preflight request are allowed
+func (c *cors) allowPreflight(req *http.Request) bool {
+	method := req.Header.Get("Access-Control-Request-Method")
+	if !c.methods[method] && method != http.MethodOptions {
+		return false
+	}
+	for _, header := range strings.Split(req.Header.Get("Access-Control-Request-Headers"), ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !c.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// respondWithError writes a types.ErrorResponse with the given status
+func respondWithError(w http.ResponseWriter, status int, code, message string, details interface{}) {
+	response, _ := json.Marshal(types.ErrorResponse{Code: code, Message: message, Details: details})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(response)
+}
+
+func resourceNotFound(w http.ResponseWriter, format string, args ...interface{}) {
+	respondWithError(w, http.StatusNotFound, types.ErrCodeNotFound, fmt.Sprintf(format, args...), nil)
+}
+
+func badRequest(w http.ResponseWriter, format string, args ...interface{}) {
+	respondWithError(w, http.StatusBadRequest, types.ErrCodeBadRequest, fmt.Sprintf(format, args...), nil)
+}
+
+// internalError hides the cause of the error from the client. It should be
+// logged instead.
+func internalError(w http.ResponseWriter) {
+	respondWithError(w, http.StatusInternalServerError, types.ErrCodeInternal, "internal server error", nil)
+}
+
+func badGateway(w http.ResponseWriter, format string, args ...interface{}) {
+	respondWithError(w, http.StatusBadGateway, types.ErrCodeUpstream, fmt.Sprintf(format, args...), nil)
+}
+
+func unprocessable(w http.ResponseWriter, invalid *types.ValidationError) {
+	respondWithError(w, http.StatusUnprocessableEntity, types.ErrCodeValidation, "document does not match the schema", invalid.Errors)
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="skychart"`)
+	respondWithError(w, http.StatusUnauthorized, types.ErrCodeUnauthorized, message, nil)
+}
+
+func forbidden(w http.ResponseWriter, message string) {
+	respondWithError(w, http.StatusForbidden, types.ErrCodeForbidden, message, nil)
+}
+
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	respondWithError(w, http.StatusTooManyRequests, types.ErrCodeRateLimited, fmt.Sprintf("rate limit exceeded, retry in %ds", seconds), nil)
+}
+
+func methodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	respondWithError(w, http.StatusMethodNotAllowed, types.ErrCodeMethodNotAllowed, fmt.Sprintf("method %s is not allowed", req.Method), nil)
+}
+
+func noContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// routeNotFound responds to requests that don't match any route
+func routeNotFound(w http.ResponseWriter, req *http.Request) {
+	resourceNotFound(w, "no route for %s", req.URL.Path)
+}
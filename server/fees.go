@@ -0,0 +1,108 @@
+package server
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// defaultGas is used when no gas amount is provided. It matches the default
+// gas limit of the Cosmos SDK.
+const defaultGas = 200000
+
+// Fees estimates the fee for each of a chain's fee tokens for the amount of
+// gas provided by the "gas" query parameter. If the low gas price isn't set
+// the fee token's fixed minimum gas price is used. Average and high gas
+// prices fall back to the next lowest price. Fee tokens with neither a low
+// nor a fixed minimum gas price are skipped rather than quoted as free.
+func (h Handler) Fees(res http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	chainName, ok := vars["chain"]
+	if !ok {
+		badRequest(res, "missing chain")
+		return
+	}
+
+	gas := uint64(defaultGas)
+	if param := req.URL.Query().Get("gas"); param != "" {
+		var err error
+		gas, err = strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			badRequest(res, "invalid gas %q", param)
+			return
+		}
+	}
+
+	reg := h.snapshot()
+	exists, chain := reg.findChain(chainName)
+	if !exists {
+		resourceNotFound(res, "chain %s not found", chainName)
+		return
+	}
+
+	estimates := make([]types.FeeEstimate, 0)
+	if chain.Fees == nil {
+		respondWithJSON(res, estimates)
+		return
+	}
+	asset := func(denom string) (types.AssetElement, bool) {
+		return reg.findAssetByDenom(denom, chain.ChainName)
+	}
+	for _, token := range chain.Fees.FeeTokens {
+		if token.LowGasPrice == nil && token.FixedMinGasPrice == nil {
+			continue
+		}
+		low := gasPrice(token.LowGasPrice, token.FixedMinGasPrice)
+		average := gasPrice(token.AverageGasPrice, &low)
+		high := gasPrice(token.HighGasPrice, &average)
+		estimates = append(estimates, types.FeeEstimate{
+			Denom:   token.Denom,
+			Gas:     gas,
+			Low:     estimateFee(gas, token.Denom, low, asset),
+			Average: estimateFee(gas, token.Denom, average, asset),
+			High:    estimateFee(gas, token.Denom, high, asset),
+		})
+	}
+	respondWithJSON(res, estimates)
+}
+
+func gasPrice(price, fallback *float64) float64 {
+	switch {
+	case price != nil:
+		return *price
+	case fallback != nil:
+		return *fallback
+	default:
+		return 0
+	}
+}
+
+// estimateFee multiplies the gas by the gas price, rounding up to the nearest
+// whole unit of the base denom
+func estimateFee(gas uint64, denom string, price float64, asset func(string) (types.AssetElement, bool)) types.FeeAmount {
+	// use the shortest decimal representation of the price so that i.e. 0.025
+	// isn't treated as 0.025000000000000001387...
+	priceStr := strconv.FormatFloat(price, 'f', -1, 64)
+	rat, _ := new(big.Rat).SetString(priceStr)
+	rat.Mul(rat, new(big.Rat).SetInt(new(big.Int).SetUint64(gas)))
+
+	fee, rem := new(big.Int).QuoRem(rat.Num(), rat.Denom(), new(big.Int))
+	if rem.Sign() > 0 {
+		fee.Add(fee, big.NewInt(1))
+	}
+
+	amount := types.FeeAmount{
+		GasPrice: priceStr,
+		Amount:   types.Amount{Amount: fee.String(), Denom: denom},
+	}
+	if asset, ok := asset(denom); ok {
+		if display, err := asset.Convert(fee.String(), denom, asset.Display); err == nil {
+			amount.Display = &types.Amount{Amount: display, Denom: asset.Display}
+		}
+	}
+	return amount
+}
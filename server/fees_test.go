@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+func TestEstimateFee(t *testing.T) {
+	atom := types.AssetElement{
+		Base:    "uatom",
+		Display: "atom",
+		DenomUnits: []types.DenomUnitElement{
+			{Denom: "uatom", Exponent: 0},
+			{Denom: "atom", Exponent: 6},
+		},
+	}
+	asset := func(denom string) (types.AssetElement, bool) {
+		return atom, denom == "uatom"
+	}
+	testCases := []struct {
+		name     string
+		gas      uint64
+		price    float64
+		fee      string
+		gasPrice string
+	}{
+		{"whole units", 200000, 0.025, "5000", "0.025"},
+		{"rounds up", 1, 0.025, "1", "0.025"},
+		{"rounds up a remainder", 3, 0.01, "1", "0.01"},
+		{"shortest representation of the price", 100, 0.1, "10", "0.1"},
+		{"no gas", 0, 0.025, "0", "0.025"},
+		{"free", 200000, 0, "0", "0"},
+		{"tiny price", 1, 1e-30, "1", "0.000000000000000000000000000001"},
+		{"large gas", math.MaxUint64, 0.5, "9223372036854775808", "0.5"},
+		{"large price", 2, 1e21, "2000000000000000000000", "1000000000000000000000"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			amount := estimateFee(tc.gas, "uatom", tc.price, asset)
+			if amount.Amount != (types.Amount{Amount: tc.fee, Denom: "uatom"}) {
+				t.Fatalf("expected %s uatom, got %+v", tc.fee, amount.Amount)
+			}
+			if amount.GasPrice != tc.gasPrice {
+				t.Fatalf("expected gas price %s, got %s", tc.gasPrice, amount.GasPrice)
+			}
+			display, err := atom.Convert(tc.fee, "uatom", "atom")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if amount.Display == nil || *amount.Display != (types.Amount{Amount: display, Denom: "atom"}) {
+				t.Fatalf("expected %s atom, got %+v", display, amount.Display)
+			}
+		})
+	}
+
+	if amount := estimateFee(1, "uosmo", 0.025, asset); amount.Display != nil {
+		t.Fatalf("expected no display amount of an unknown asset, got %+v", amount.Display)
+	}
+}
+
+func TestFeeTiers(t *testing.T) {
+	_, _, router := newTestHandler(t, map[string]string{
+		"cosmoshub/chain.json": `{"chain_name":"cosmoshub","chain_id":"cosmoshub-4","fees":{"fee_tokens":[
+			{"denom":"all","low_gas_price":0.01,"average_gas_price":0.02,"high_gas_price":0.03},
+			{"denom":"low","low_gas_price":0.01},
+			{"denom":"fixed","fixed_min_gas_price":0.005,"high_gas_price":0.03},
+			{"denom":"low-and-fixed","low_gas_price":0.01,"fixed_min_gas_price":0.005},
+			{"denom":"average-only","average_gas_price":0.02},
+			{"denom":"none"}
+		]}}`,
+	})
+	rec := serveTest(router, http.MethodGet, "/v1/chain/cosmoshub/fees?gas=1000")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var estimates []types.FeeEstimate
+	if err := json.Unmarshal(rec.Body.Bytes(), &estimates); err != nil {
+		t.Fatal(err)
+	}
+
+	// low, average and high fees of each token that can be quoted
+	expected := map[string][3]int{
+		"all":           {10, 20, 30},
+		"low":           {10, 10, 10},
+		"fixed":         {5, 5, 30},
+		"low-and-fixed": {10, 10, 10},
+	}
+	if len(estimates) != len(expected) {
+		t.Fatalf("expected %d estimates, got %+v", len(expected), estimates)
+	}
+	for _, estimate := range estimates {
+		fees, ok := expected[estimate.Denom]
+		if !ok {
+			t.Fatalf("unexpected estimate for %s", estimate.Denom)
+		}
+		if estimate.Gas != 1000 {
+			t.Fatalf("expected 1000 gas, got %d", estimate.Gas)
+		}
+		for idx, amount := range []types.FeeAmount{estimate.Low, estimate.Average, estimate.High} {
+			if amount.Amount.Amount != strconv.Itoa(fees[idx]) {
+				t.Errorf("%s: expected fees %v, got %s at tier %d", estimate.Denom, fees, amount.Amount.Amount, idx)
+			}
+		}
+	}
+
+	if rec := serveTest(router, http.MethodGet, "/v1/chain/cosmoshub/fees?gas=-1"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for negative gas, got %d", rec.Code)
+	}
+}
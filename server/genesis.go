@@ -0,0 +1,405 @@
+package server
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// Genesis serves the genesis file of a chain. The file is fetched from the
+// chain's genesis url on first request, decompressed, verified and cached on
+// disk. Range requests are supported.
+func (h Handler) Genesis(res http.ResponseWriter, req *http.Request) {
+	entry, file, ok := h.genesisEntry(res, req)
+	if !ok {
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		internalError(res)
+		return
+	}
+
+	sum, _ := hex.DecodeString(entry.SHA256)
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum))
+	res.Header().Set("X-Checksum-Sha256", entry.SHA256)
+	// the file only changes if its checksum does
+	res.Header().Set("ETag", `"`+entry.SHA256+`"`)
+	res.Header().Set("Cache-Control", h.cacheControl)
+	http.ServeContent(res, req, "genesis.json", info.ModTime(), file)
+}
+
+// GenesisChecksum returns the SHA-256 checksum of a chain's genesis file,
+// fetching it first if it has not yet been cached
+func (h Handler) GenesisChecksum(res http.ResponseWriter, req *http.Request) {
+	entry, file, ok := h.genesisEntry(res, req)
+	if !ok {
+		return
+	}
+	file.Close()
+	respondWithJSON(res, entry.GenesisChecksum)
+}
+
+// genesisEntry resolves the chain of the request to a cached genesis file,
+// which the caller must close, writing the appropriate error response if this
+// is not possible
+func (h Handler) genesisEntry(res http.ResponseWriter, req *http.Request) (genesisEntry, *os.File, bool) {
+	if h.genesis == nil {
+		resourceNotFound(res, "genesis files are not served")
+		return genesisEntry{}, nil, false
+	}
+
+	vars := mux.Vars(req)
+	chainName, ok := vars["chain"]
+	if !ok {
+		badRequest(res, "missing chain")
+		return genesisEntry{}, nil, false
+	}
+
+	exists, chain := h.snapshot().findChain(chainName)
+	if !exists {
+		resourceNotFound(res, "chain %s not found", chainName)
+		return genesisEntry{}, nil, false
+	}
+	if chain.Genesis == nil || chain.Genesis.GenesisURL == nil {
+		resourceNotFound(res, "chain %s has no genesis file", chainName)
+		return genesisEntry{}, nil, false
+	}
+
+	entry, file, err := h.genesis.get(req.Context(), chain)
+	if err != nil {
+		h.requestLog(req).Warn("fetching genesis", "chain", chain.ChainName, "err", err)
+		badGateway(res, "fetching the genesis file of %s failed", chain.ChainName)
+		return genesisEntry{}, nil, false
+	}
+	return entry, file, true
+}
+
+const (
+	// genesisFetchTimeout bounds how long a genesis file may take to download
+	genesisFetchTimeout = 30 * time.Minute
+	// maxGenesisSize bounds the size of a genesis file, both as downloaded
+	// and once decompressed
+	maxGenesisSize = 16 << 30
+)
+
+// genesisCache stores decompressed genesis files on disk alongside a small
+// metadata file recording where they came from and their checksum, both in
+// a directory per chain. Each file is downloaded at most once at a time;
+// concurrent requests wait for the same download.
+type genesisCache struct {
+	dir     string
+	client  *http.Client
+	maxSize int64
+	// downloads run on this context rather than that of the request that
+	// started them, so that one client disconnecting doesn't abort them for
+	// everyone else. It is cancelled when the server shuts down.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mtx guards inflight and keeps each genesis file and its metadata
+	// consistent: both are replaced and opened while holding it
+	mtx      sync.Mutex
+	inflight map[string]*genesisFetch // chain name -> running download
+}
+
+// genesisFetch is a download shared by all requests for a chain's genesis
+type genesisFetch struct {
+	done chan struct{}
+	err  error
+}
+
+type genesisEntry struct {
+	types.GenesisChecksum
+	ChainName string `json:"chain_name"`
+}
+
+func newGenesisCache(dir string) *genesisCache {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &genesisCache{
+		dir:      dir,
+		client:   &http.Client{Timeout: genesisFetchTimeout},
+		maxSize:  maxGenesisSize,
+		ctx:      ctx,
+		cancel:   cancel,
+		inflight: make(map[string]*genesisFetch),
+	}
+}
+
+// stop aborts running downloads
+func (c *genesisCache) stop() {
+	c.cancel()
+}
+
+func (c *genesisCache) path(chainName string) string {
+	return filepath.Join(c.dir, chainName, "genesis.json")
+}
+
+func (c *genesisCache) metaPath(chainName string) string {
+	return filepath.Join(c.dir, chainName, "meta.json")
+}
+
+// get returns the cached genesis entry for the chain along with its file,
+// which the caller must close, fetching it if it doesn't exist or if the
+// chain's genesis url has since changed. ctx only bounds how long the caller
+// waits, not the download itself.
+func (c *genesisCache) get(ctx context.Context, chain types.Chain) (genesisEntry, *os.File, error) {
+	genesisURL := *chain.Genesis.GenesisURL
+
+	c.mtx.Lock()
+	fetch, ok := c.inflight[chain.ChainName]
+	if !ok {
+		entry, file, err := c.open(chain.ChainName)
+		if err == nil && entry.URL == genesisURL && entry.ChainID == chain.ChainID {
+			c.mtx.Unlock()
+			return entry, file, nil
+		}
+		if file != nil {
+			file.Close()
+		}
+		fetch = &genesisFetch{done: make(chan struct{})}
+		c.inflight[chain.ChainName] = fetch
+		go c.run(fetch, chain.ChainName, chain.ChainID, genesisURL)
+	}
+	c.mtx.Unlock()
+
+	select {
+	case <-fetch.done:
+		if fetch.err != nil {
+			return genesisEntry{}, nil, fetch.err
+		}
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		return c.open(chain.ChainName)
+	case <-ctx.Done():
+		return genesisEntry{}, nil, ctx.Err()
+	}
+}
+
+// run downloads a genesis file into the cache
+func (c *genesisCache) run(fetch *genesisFetch, chainName, chainID, genesisURL string) {
+	defer func() {
+		c.mtx.Lock()
+		delete(c.inflight, chainName)
+		c.mtx.Unlock()
+		close(fetch.done)
+	}()
+
+	ctx, cancel := context.WithTimeout(c.ctx, genesisFetchTimeout)
+	defer cancel()
+	fetch.err = c.fetch(ctx, chainName, chainID, genesisURL)
+}
+
+// open reads the metadata of a chain's cached genesis file and opens the
+// file. The caller must hold c.mtx so that both belong to the same download.
+func (c *genesisCache) open(chainName string) (genesisEntry, *os.File, error) {
+	var entry genesisEntry
+	bz, err := ioutil.ReadFile(c.metaPath(chainName))
+	if err != nil {
+		return entry, nil, err
+	}
+	if err := json.Unmarshal(bz, &entry); err != nil {
+		return entry, nil, err
+	}
+	file, err := os.Open(c.path(chainName))
+	if err != nil {
+		return entry, nil, err
+	}
+	return entry, file, nil
+}
+
+// commit moves a downloaded genesis file into place and records its
+// metadata. The old metadata is removed first so that an interrupted commit
+// leads to the file being fetched again rather than being served with the
+// wrong checksum.
+func (c *genesisCache) commit(tmpPath string, entry genesisEntry) error {
+	bz, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if err := os.Remove(c.metaPath(entry.ChainName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(tmpPath, c.path(entry.ChainName)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.metaPath(entry.ChainName), bz, 0o644)
+}
+
+// fetch downloads the genesis file, decompressing it if necessary, and checks
+// that it belongs to the expected chain before moving it into the cache
+func (c *genesisCache) fetch(ctx context.Context, chainName, chainID, genesisURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, genesisURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from query %s: %d", genesisURL, resp.StatusCode)
+	}
+	if resp.ContentLength > c.maxSize {
+		return fmt.Errorf("genesis file of %d bytes exceeds the limit of %d", resp.ContentLength, c.maxSize)
+	}
+
+	body, err := decompress(io.LimitReader(resp.Body, c.maxSize), genesisURL)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(c.dir, chainName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, "genesis.*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(body, c.maxSize+1))
+	if err != nil {
+		return err
+	}
+	if size > c.maxSize {
+		return fmt.Errorf("decompressed genesis file exceeds the limit of %d bytes", c.maxSize)
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	genesisChainID, err := readChainID(bufio.NewReader(tmp))
+	if err != nil {
+		return fmt.Errorf("parsing genesis: %w", err)
+	}
+	if genesisChainID != chainID {
+		return fmt.Errorf("genesis chain id %q does not match registered chain id %q", genesisChainID, chainID)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return c.commit(tmp.Name(), genesisEntry{
+		ChainName: chainName,
+		GenesisChecksum: types.GenesisChecksum{
+			ChainID: chainID,
+			URL:     genesisURL,
+			SHA256:  hex.EncodeToString(hash.Sum(nil)),
+			Size:    size,
+		},
+	})
+}
+
+// decompress unwraps gzip compressed and tar archived genesis files. Gzip is
+// detected from the content itself whereas tar archives are detected from
+// the file extension of the url's path.
+func decompress(r io.Reader, rawURL string) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	r = buffered
+	if magic[0] == 0x1f && magic[1] == 0x8b {
+		r, err = gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	path := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		path = u.Path
+	}
+	if !strings.HasSuffix(path, ".tar") && !strings.HasSuffix(path, ".tar.gz") && !strings.HasSuffix(path, ".tgz") {
+		return r, nil
+	}
+
+	// use the first json file in the archive
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil, errors.New("no json file found in genesis archive")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && strings.HasSuffix(header.Name, ".json") {
+			return archive, nil
+		}
+	}
+}
+
+// readChainID streams through a genesis file to find the top level chain_id
+// without holding the entire file (which can be several gigabytes) in memory
+func readChainID(r io.Reader) (string, error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return "", errors.New("expected genesis to be a json object")
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if key == "chain_id" {
+			var chainID string
+			err := dec.Decode(&chainID)
+			return chainID, err
+		}
+		if err := skipValue(dec); err != nil {
+			return "", err
+		}
+	}
+	return "", errors.New("genesis has no chain_id")
+}
+
+// skipValue consumes the next json value from the decoder token by token
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+const testGenesis = `{"genesis_time":"2019-12-11T16:11:34Z","chain_id":"cosmoshub-4","app_state":{"bank":{"balances":[]}}}`
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarred(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func genesisChain(chainID, genesisURL string) types.Chain {
+	return types.Chain{ChainName: "cosmoshub", ChainID: chainID, Genesis: &types.Genesis{GenesisURL: &genesisURL}}
+}
+
+func TestGenesisCacheFormats(t *testing.T) {
+	files := map[string][]byte{
+		"/genesis.json":    []byte(testGenesis),
+		"/genesis.json.gz": gzipped(t, []byte(testGenesis)),
+		"/genesis.tar.gz":  gzipped(t, tarred(t, "genesis.json", []byte(testGenesis))),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	sum := sha256.Sum256([]byte(testGenesis))
+	for path := range files {
+		t.Run(path, func(t *testing.T) {
+			cache := newGenesisCache(t.TempDir())
+			// the query string must not hide the archive's extension
+			entry, file, err := cache.get(context.Background(), genesisChain("cosmoshub-4", srv.URL+path+"?download=1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			if entry.SHA256 != hex.EncodeToString(sum[:]) || entry.Size != int64(len(testGenesis)) {
+				t.Fatalf("unexpected entry %+v", entry)
+			}
+			data, err := io.ReadAll(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != testGenesis {
+				t.Fatalf("unexpected genesis %s", data)
+			}
+		})
+	}
+}
+
+func TestGenesisCacheErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testGenesis))
+	}))
+	defer srv.Close()
+
+	cache := newGenesisCache(t.TempDir())
+	if _, _, err := cache.get(context.Background(), genesisChain("osmosis-1", srv.URL)); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected a chain id mismatch, got %v", err)
+	}
+
+	cache = newGenesisCache(t.TempDir())
+	cache.maxSize = 16
+	if _, _, err := cache.get(context.Background(), genesisChain("cosmoshub-4", srv.URL)); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Fatalf("expected the size limit to be exceeded, got %v", err)
+	}
+}
+
+// A request that gives up waiting must not abort the download for others
+func TestGenesisCacheSharedDownload(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_, _ = w.Write([]byte(testGenesis))
+	}))
+	defer srv.Close()
+
+	cache := newGenesisCache(t.TempDir())
+	chain := genesisChain("cosmoshub-4", srv.URL)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := cache.get(cancelled, chain)
+		errs <- err
+	}()
+
+	var wg sync.WaitGroup
+	results := make([]error, 3)
+	for idx := range results {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			_, file, err := cache.get(context.Background(), chain)
+			if err == nil {
+				file.Close()
+			}
+			results[idx] = err
+		}(idx)
+	}
+
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled request to return, got %v", err)
+	}
+	close(release)
+	wg.Wait()
+	for _, err := range results {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("expected a single download, got %d", n)
+	}
+
+	// the cached file is served without downloading it again
+	_, file, err := cache.get(context.Background(), chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("expected the cached file to be used, got %d downloads", n)
+	}
+}
+
+// Chains whose names only differ by a suffix must not share cache files
+func TestGenesisCacheLayout(t *testing.T) {
+	genesis := map[string]string{
+		"/cosmoshub":      `{"chain_id":"cosmoshub-4"}`,
+		"/cosmoshub.meta": `{"chain_id":"meta-1"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(genesis[r.URL.Path]))
+	}))
+	defer srv.Close()
+
+	cache := newGenesisCache(t.TempDir())
+	meta := genesisChain("meta-1", srv.URL+"/cosmoshub.meta")
+	meta.ChainName = "cosmoshub.meta"
+	chains := []types.Chain{genesisChain("cosmoshub-4", srv.URL+"/cosmoshub"), meta}
+	// fetch each chain twice so that the second read comes from the cache
+	for i := 0; i < 2; i++ {
+		for _, chain := range chains {
+			entry, file, err := cache.get(context.Background(), chain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := genesis["/"+chain.ChainName]; string(data) != expected || entry.ChainID != chain.ChainID {
+				t.Fatalf("expected %s for %s, got %s (%+v)", expected, chain.ChainName, data, entry)
+			}
+		}
+	}
+}
+
+// A file that is open while the genesis is fetched again keeps matching the
+// checksum it was returned with
+func TestGenesisCacheRefetch(t *testing.T) {
+	var requests atomic.Int32
+	genesis := map[string]string{
+		"/v1": `{"chain_id":"cosmoshub-4","version":1}`,
+		"/v2": `{"chain_id":"cosmoshub-4","version":2}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(genesis[r.URL.Path]))
+	}))
+	defer srv.Close()
+
+	cache := newGenesisCache(t.TempDir())
+	checkFile := func(entry genesisEntry, file *os.File) {
+		t.Helper()
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			t.Fatal(err)
+		}
+		if sum := hex.EncodeToString(hash.Sum(nil)); sum != entry.SHA256 {
+			t.Fatalf("expected the file of %s to have checksum %s, got %s", entry.URL, entry.SHA256, sum)
+		}
+	}
+
+	first, firstFile, err := cache.get(context.Background(), genesisChain("cosmoshub-4", srv.URL+"/v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer firstFile.Close()
+	second, secondFile, err := cache.get(context.Background(), genesisChain("cosmoshub-4", srv.URL+"/v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer secondFile.Close()
+	if first.SHA256 == second.SHA256 {
+		t.Fatal("expected the changed genesis url to be fetched again")
+	}
+	checkFile(first, firstFile)
+	checkFile(second, secondFile)
+
+	// a commit that was interrupted before writing the metadata leads to the
+	// file being fetched again
+	if err := os.Remove(cache.metaPath("cosmoshub")); err != nil {
+		t.Fatal(err)
+	}
+	entry, file, err := cache.get(context.Background(), genesisChain("cosmoshub-4", srv.URL+"/v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	checkFile(entry, file)
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("expected 3 downloads, got %d", n)
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// GitSource reads the registry from a shallow clone of a git repository. The
+// clone is created on the first call to Revision and subsequently updated by
+// fetching only the latest commit of the ref. Files are read straight from
+// the working tree. Any remote that git understands can be used, including a
+// path to a local bare repository. It requires the git binary.
+type GitSource struct {
+	remote string
+	ref    string
+	dir    string
+
+	// mtx guards the working tree while it is being updated
+	mtx sync.RWMutex
+}
+
+var _ Source = (*GitSource)(nil)
+
+// NewGitSource creates a source that clones remote into dir and tracks ref,
+// which may be a branch or tag. If ref is empty, the remote's default branch
+// is used.
+func NewGitSource(remote, ref, dir string) *GitSource {
+	return &GitSource{remote: remote, ref: ref, dir: dir}
+}
+
+// String describes the remote and ref that are tracked
+func (s *GitSource) String() string {
+	if s.ref == "" {
+		return "git:" + redactURL(s.remote)
+	}
+	return "git:" + redactURL(s.remote) + "#" + s.ref
+}
+
+// Revision fetches the latest commit of the ref, checks it out and returns
+// its sha
+func (s *GitSource) Revision(ctx context.Context) (string, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, err := os.Stat(filepath.Join(s.dir, ".git")); os.IsNotExist(err) {
+		if err := s.clone(ctx); err != nil {
+			return "", err
+		}
+	} else {
+		ref := s.ref
+		if ref == "" {
+			ref = "HEAD"
+		}
+		if _, err := s.git(ctx, "fetch", "--depth", "1", "--no-tags", "origin", ref); err != nil {
+			return "", err
+		}
+		if _, err := s.git(ctx, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+	}
+
+	sha, err := s.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sha), nil
+}
+
+func (s *GitSource) clone(ctx context.Context) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	args := []string{"clone", "--depth", "1", "--single-branch", "--no-tags"}
+	if s.ref != "" {
+		args = append(args, "--branch", s.ref)
+	}
+	args = append(args, "--", s.remote, s.dir)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	return runGit(cmd)
+}
+
+// Chains lists the chain directories in the working tree
+func (s *GitSource) Chains(ctx context.Context) ([]string, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	chains := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() && isChainDir(entry.Name()) {
+			chains = append(chains, entry.Name())
+		}
+	}
+	return chains, nil
+}
+
+// ReadFile reads a file from the working tree
+func (s *GitSource) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return nil, fmt.Errorf("invalid path %q", path)
+	}
+	return os.ReadFile(filepath.Join(s.dir, clean))
+}
+
+// git runs a git command in the clone and returns its output
+func (s *GitSource) git(ctx context.Context, args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", s.dir}, args...)...)
+	cmd.Stdout = &out
+	if err := runGit(cmd); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func runGit(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// never prompt for credentials
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", strings.Join(cmd.Args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
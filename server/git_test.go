@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runTestGit runs a git command in dir with a fixed identity
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=skychart", "-c", "user.email=skychart@example.com"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commitChain writes a chain.json to the work tree, commits it and pushes it
+// to the bare remote, returning the sha of the commit
+func commitChain(t *testing.T, work, chain, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(work, chain), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(work, chain, "chain.json"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runTestGit(t, work, "add", "-A")
+	runTestGit(t, work, "commit", "-q", "-m", "update "+chain)
+	runTestGit(t, work, "push", "-q", "origin", "main")
+	return runTestGit(t, work, "rev-parse", "HEAD")
+}
+
+func TestGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	for _, ref := range []string{"", "main"} {
+		t.Run("ref="+ref, func(t *testing.T) {
+			root := t.TempDir()
+			remote, work := filepath.Join(root, "remote.git"), filepath.Join(root, "work")
+			for _, dir := range []string{remote, work} {
+				if err := os.Mkdir(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			runTestGit(t, remote, "init", "-q", "--bare")
+			runTestGit(t, remote, "symbolic-ref", "HEAD", "refs/heads/main")
+			runTestGit(t, work, "init", "-q")
+			runTestGit(t, work, "symbolic-ref", "HEAD", "refs/heads/main")
+			runTestGit(t, work, "remote", "add", "origin", remote)
+			first := commitChain(t, work, "cosmoshub", `{"chain_name":"cosmoshub"}`)
+
+			source := NewGitSource(remote, ref, filepath.Join(root, "clone"))
+
+			// the first revision clones the remote
+			sha, err := source.Revision(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sha != first {
+				t.Fatalf("expected revision %s, got %s", first, sha)
+			}
+			chains, err := source.Chains(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(chains) != 1 || chains[0] != "cosmoshub" {
+				t.Fatalf("expected [cosmoshub], got %v", chains)
+			}
+
+			// later revisions fetch and reset to the new commit
+			second := commitChain(t, work, "osmosis", `{"chain_name":"osmosis"}`)
+			sha, err = source.Revision(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sha != second {
+				t.Fatalf("expected revision %s, got %s", second, sha)
+			}
+			chains, err = source.Chains(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(chains) != 2 {
+				t.Fatalf("expected 2 chains, got %v", chains)
+			}
+			data, err := source.ReadFile(ctx, "osmosis/chain.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != `{"chain_name":"osmosis"}` {
+				t.Fatalf("unexpected chain.json %s", data)
+			}
+
+			if _, err := source.ReadFile(ctx, "../remote.git/HEAD"); err == nil {
+				t.Fatal("expected a path outside the clone to be rejected")
+			}
+		})
+	}
+}
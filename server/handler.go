@@ -2,46 +2,120 @@ package server
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
-	"time"
+	"sync"
+	"sync/atomic"
 
 	"github.com/gorilla/mux"
-
-	"github.com/cmwaters/skychart/types"
 )
 
 // Handler is the core object in the server package. It keeps an in-memory state
 // of the chain-registry which can be updated using `Pull`. It handles requests
 // for this data through the router.
 type Handler struct {
-	registryUrl  string
-	lastUpdated  time.Time
-	chains       []string
-	assets       []string
-	chainByAsset map[string]string // asset name -> chain name
-	chainById    map[string]string // chain id -> chain name
-	chainList    map[string]types.Chain
-	assetList    map[string]types.AssetList
-	log          *log.Logger
+	registryUrl string
+	source      Source
+	// local overrides merged over the registry and the source beneath them.
+	// Nil if overrides are disabled.
+	overrides *overrideStore
+	upstream  Source
+	auth      *authenticator
+	// pullRequests asks Serve to pull the registry outside of the schedule
+	pullRequests chan struct{}
+	// current is the latest complete snapshot of the registry. Pull swaps in
+	// a new snapshot once it has been fully built.
+	current *atomic.Pointer[registry]
+	// pulling ensures that only one Pull runs at a time
+	pulling *sync.Mutex
+	// the number of chains fetched in parallel during a pull
+	concurrency int
+	// Cache-Control header of responses derived from the registry
+	cacheControl string
+	genesis      *genesisCache
+	metrics      *metrics
+	status       *status
+	log          *slog.Logger
 }
 
-func NewHandler(registryUrl string, log *log.Logger) *Handler {
-	return &Handler{
+func NewHandler(registryUrl string, log *slog.Logger, opts ...Option) *Handler {
+	o := newOptions(opts)
+
+	h := &Handler{
 		registryUrl:  registryUrl,
-		lastUpdated:  time.Unix(0, 0),
-		chains:       make([]string, 0),
-		assets:       make([]string, 0),
-		chainByAsset: make(map[string]string),
-		chainById:    make(map[string]string),
-		chainList:    make(map[string]types.Chain),
-		assetList:    make(map[string]types.AssetList),
+		current:      new(atomic.Pointer[registry]),
+		pulling:      new(sync.Mutex),
+		concurrency:  o.concurrency,
+		cacheControl: cacheControl(o.cacheMaxAge, o.auth.RequireKey),
+		pullRequests: make(chan struct{}, 1),
+		metrics:      newMetrics(),
 		log:          log,
 	}
+	h.current.Store(newRegistry())
+	h.source = o.source
+	if h.source == nil {
+		h.source = NewGitHubSource(registryUrl, o.requestTimeout)
+	}
+	// the admin token is an API key with admin scope
+	auth := o.auth
+	if o.adminToken != "" {
+		auth.Keys = append(auth.Keys[:len(auth.Keys):len(auth.Keys)], APIKey{Name: "admin-token", Key: o.adminToken, Scope: ScopeAdmin})
+	}
+	h.auth = newAuthenticator(auth)
+	if o.overridesDir != "" {
+		// overrides are the highest priority layer
+		h.overrides = newOverrideStore(o.overridesDir)
+		h.upstream = h.source
+		layers := []Layer{{Name: "upstream", Source: h.source}}
+		if layered, ok := h.source.(*LayeredSource); ok {
+			layers = layered.layers
+		}
+		h.source = NewLayeredSource(append(layers[:len(layers):len(layers)], Layer{Name: "local", Source: h.overrides})...)
+	}
+	if source, ok := h.source.(metricsUser); ok {
+		source.useMetrics(h.metrics)
+	}
+	if o.genesisDir != "" {
+		h.genesis = newGenesisCache(o.genesisDir)
+	}
+	h.status = newStatus(h.source.String())
+	return h
+}
+
+// snapshot returns the current state of the registry. It must be treated as
+// read only.
+func (h Handler) snapshot() *registry {
+	return h.current.Load()
 }
 
+// Chains returns the names of all chains. The list parameters page, sort
+// and expand them into full or sparse chain objects.
 func (h Handler) Chains(res http.ResponseWriter, req *http.Request) {
-	respondWithJSON(res, h.chains)
+	reg := h.snapshot()
+	query := req.URL.Query()
+	if !isListQuery(query) {
+		respondWithPayload(res, req, reg.rendered[renderKey("chains", "")], reg.chains)
+		return
+	}
+	q, ok := parseListQuery(res, query, "name", "chain_id")
+	if !ok {
+		return
+	}
+
+	items := make([]listItem, len(reg.chains))
+	for idx, name := range reg.chains {
+		items[idx] = listItem{name: name, key: name}
+		if q.sort == "chain_id" {
+			items[idx].key = reg.chainList[name].ChainID
+		}
+	}
+	respondWithList(res, q, items, func(name string) interface{} {
+		// chains without a chain.json expand to null
+		if chain, ok := reg.chainList[name]; ok {
+			return chain
+		}
+		return nil
+	})
 }
 
 // Chain searches for a chain by either name or ID and
@@ -50,36 +124,53 @@ func (h Handler) Chain(res http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	chainName, ok := vars["chain"]
 	if !ok {
-		badRequest(res)
+		badRequest(res, "missing chain")
 		return
 	}
 
-	exists, chain := h.findChain(chainName)
+	reg := h.snapshot()
+	exists, chain := reg.findChain(chainName)
 	if !exists {
-		resourceNotFound(res)
+		resourceNotFound(res, "chain %s not found", chainName)
 		return
 	}
-	respondWithJSON(res, chain)
+	if _, ok := reg.chainList[chainName]; !ok {
+		chainName = reg.chainById[chainName]
+	}
+	respondWithPayload(res, req, reg.rendered[renderKey("chain", chainName)], chain)
 }
 
 func (h Handler) Endpoints(res http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	chainName, ok := vars["chain"]
 	if !ok {
-		badRequest(res)
+		badRequest(res, "missing chain")
 		return
 	}
 	endpointType, ok := vars["type"]
 	if !ok {
-		badRequest(res)
+		badRequest(res, "missing endpoint type")
 		return
 	}
-	exists, chain := h.findChain(chainName)
+	exists, chain := h.snapshot().findChain(chainName)
 	if !exists {
-		resourceNotFound(res)
+		resourceNotFound(res, "chain %s not found", chainName)
 		return
 	}
 
+	switch endpointType {
+	case "rpc", "grpc", "rest":
+		if chain.Apis == nil {
+			resourceNotFound(res, "chain %s has no apis", chain.ChainName)
+			return
+		}
+	case "peers", "seeds":
+		if chain.Peers == nil {
+			resourceNotFound(res, "chain %s has no peers", chain.ChainName)
+			return
+		}
+	}
+
 	switch endpointType {
 	case "rpc":
 		respondWithJSON(res, chain.Apis.RPC)
@@ -92,7 +183,7 @@ func (h Handler) Endpoints(res http.ResponseWriter, req *http.Request) {
 	case "seeds":
 		respondWithJSON(res, chain.Peers.Seeds)
 	default:
-		badRequest(res)
+		badRequest(res, "unknown endpoint type %s, expected rpc, grpc, rest, peers or seeds", endpointType)
 	}
 }
 
@@ -100,83 +191,83 @@ func (h Handler) ChainAsset(res http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	chainName, ok := vars["chain"]
 	if !ok {
-		badRequest(res)
+		badRequest(res, "missing chain")
 		return
 	}
-	assets, ok := h.assetList[chainName]
+	reg := h.snapshot()
+	assets, ok := reg.assetList[chainName]
 	if !ok {
-		chainName, ok = h.chainById[chainName]
+		name, ok := reg.chainById[chainName]
 		if !ok {
-			badRequest(res)
+			resourceNotFound(res, "asset list of chain %s not found", chainName)
+			return
 		}
-		assets = h.assetList[chainName]
+		chainName = name
+		assets = reg.assetList[name]
 	}
-	respondWithJSON(res, assets)
+	respondWithPayload(res, req, reg.rendered[renderKey("assetlist", chainName)], assets)
 }
 
+// Assets returns the display names of all assets. The list parameters page,
+// sort and expand them like Chains. Sorting by chain_id sorts by the chain
+// the asset is served from.
 func (h Handler) Assets(res http.ResponseWriter, req *http.Request) {
-	respondWithJSON(res, h.assets)
-}
-
-func (h Handler) Asset(res http.ResponseWriter, req *http.Request) {
-	vars := mux.Vars(req)
-	assetName, ok := vars["asset"]
-	if !ok {
-		badRequest(res)
+	reg := h.snapshot()
+	query := req.URL.Query()
+	if !isListQuery(query) {
+		respondWithPayload(res, req, reg.rendered[renderKey("assets", "")], reg.assets)
 		return
 	}
-	chainName, ok := h.chainByAsset[assetName]
+	q, ok := parseListQuery(res, query, "name", "chain_id")
 	if !ok {
-		resourceNotFound(res)
 		return
 	}
 
-	assetList := h.assetList[chainName]
-	for _, asset := range assetList.Assets {
-		if asset.Display == assetName {
-			respondWithJSON(res, asset)
-			return
+	items := make([]listItem, len(reg.assets))
+	for idx, name := range reg.assets {
+		items[idx] = listItem{name: name, key: name}
+		if q.sort == "chain_id" {
+			items[idx].key = reg.chainList[reg.chainByAsset[name]].ChainID
 		}
 	}
-
-	resourceNotFound(res)
+	respondWithList(res, q, items, func(name string) interface{} {
+		if asset, ok := reg.findAsset(name); ok {
+			return asset
+		}
+		return nil
+	})
 }
 
-func (h Handler) findChain(name string) (bool, types.Chain) {
-	chain, ok := h.chainList[name]
-	if ok {
-		return true, chain
+func (h Handler) Asset(res http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	assetName, ok := vars["asset"]
+	if !ok {
+		badRequest(res, "missing asset")
+		return
 	}
-
-	name, ok = h.chainById[name]
+	reg := h.snapshot()
+	asset, ok := reg.findAsset(assetName)
 	if !ok {
-		return false, types.Chain{}
+		resourceNotFound(res, "asset %s not found", assetName)
+		return
 	}
-
-	return true, h.chainList[name]
+	respondWithPayload(res, req, reg.rendered[renderKey("asset", assetName)], asset)
 }
 
 func respondWithJSON(w http.ResponseWriter, payload interface{}) {
-	response, _ := json.Marshal(payload)
+	response, err := json.Marshal(payload)
+	if err != nil {
+		internalError(w)
+		return
+	}
 
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET")
-	w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Content-Type, Access-Control-Allow-Headers, Authorization, X-Requested-With")
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write(response)
 }
 
-func resourceNotFound(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET")
-	w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Content-Type, Access-Control-Allow-Headers, Authorization, X-Requested-With")
-	w.WriteHeader(http.StatusNotFound)
-}
-
-func badRequest(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET")
-	w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Content-Type, Access-Control-Allow-Headers, Authorization, X-Requested-With")
-	w.WriteHeader(http.StatusBadRequest)
+func respondWithText(w http.ResponseWriter, payload []byte) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(payload)
 }
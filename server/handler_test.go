@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// testSource is a registry held in memory. Its files can be replaced
+// between pulls.
+type testSource struct {
+	mtx      sync.RWMutex
+	revision string
+	files    map[string]string
+}
+
+func (s *testSource) set(revision string, files map[string]string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.revision, s.files = revision, files
+}
+
+func (s *testSource) String() string {
+	return "test:memory"
+}
+
+func (s *testSource) Revision(ctx context.Context) (string, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.revision, nil
+}
+
+func (s *testSource) Chains(ctx context.Context) ([]string, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	seen := make(map[string]bool)
+	chains := make([]string, 0)
+	for name := range s.files {
+		dir, _, ok := strings.Cut(name, "/")
+		if ok && !seen[dir] && isChainDir(dir) {
+			seen[dir] = true
+			chains = append(chains, dir)
+		}
+	}
+	sort.Strings(chains)
+	return chains, nil
+}
+
+func (s *testSource) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	data, ok := s.files[path.Clean(name)]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+	return []byte(data), nil
+}
+
+// newTestHandler returns a handler that has pulled the files and a router
+// serving it
+func newTestHandler(t *testing.T, files map[string]string, opts ...Option) (*Handler, *testSource, *mux.Router) {
+	t.Helper()
+	source := &testSource{revision: "1", files: files}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := NewHandler("test", log, append([]Option{WithSource(source)}, opts...)...)
+	if err := h.Pull(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return h, source, newRouter(h)
+}
+
+// serveTest serves a request with the given headers, given as name and
+// value pairs
+func serveTest(router http.Handler, method, target string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for idx := 0; idx+1 < len(headers); idx += 2 {
+		req.Header.Set(headers[idx], headers[idx+1])
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
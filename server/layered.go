@@ -0,0 +1,282 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// keyedArrays lists the arrays whose elements are merged by a key field
+// rather than being replaced as a whole, so that a layer can add or patch a
+// single asset
+var keyedArrays = map[string]string{
+	"assets": "base",
+}
+
+// ChainProvenance reports which registry layer each field of a chain and its
+// asset list came from. Fields are empty if registries aren't merged.
+func (h Handler) ChainProvenance(res http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	chainName, ok := vars["chain"]
+	if !ok {
+		badRequest(res, "missing chain")
+		return
+	}
+
+	reg := h.snapshot()
+	if _, ok := reg.chainList[chainName]; !ok {
+		name, ok := reg.chainById[chainName]
+		if !ok {
+			resourceNotFound(res, "chain %s not found", chainName)
+			return
+		}
+		chainName = name
+	}
+	resp := types.ChainProvenance{
+		Chain:     make(map[string]string),
+		AssetList: make(map[string]string),
+	}
+	if provenance, ok := reg.provenance[chainName]; ok {
+		if provenance.Chain != nil {
+			resp.Chain = provenance.Chain
+		}
+		if provenance.AssetList != nil {
+			resp.AssetList = provenance.AssetList
+		}
+	}
+	respondWithJSON(res, resp)
+}
+
+// Layer is a named registry source used by a LayeredSource
+type Layer struct {
+	Name   string
+	Source Source
+}
+
+// LayeredSource merges several registries in priority order. Later layers add
+// chains or override individual fields of earlier ones following JSON merge
+// patch semantics (RFC 7386): objects are merged recursively, other values
+// replace the earlier value and null removes it. Assets are merged by their
+// base denom. The layer that each field came from is recorded.
+type LayeredSource struct {
+	layers []Layer
+
+	mtx        sync.Mutex
+	provenance map[string]map[string]string // file -> field path -> layer
+}
+
+var _ Source = (*LayeredSource)(nil)
+
+// NewLayeredSource creates a source merging layers, lowest priority first
+func NewLayeredSource(layers ...Layer) *LayeredSource {
+	return &LayeredSource{
+		layers:     layers,
+		provenance: make(map[string]map[string]string),
+	}
+}
+
+// String lists the layers as name=source:location, lowest priority first
+func (s *LayeredSource) String() string {
+	layers := make([]string, len(s.layers))
+	for idx, layer := range s.layers {
+		layers[idx] = layer.Name + "=" + layer.Source.String()
+	}
+	return strings.Join(layers, ",")
+}
+
+// Revision updates every layer and combines their revisions. If any layer's
+// revision is unknown, so is the combined revision.
+func (s *LayeredSource) Revision(ctx context.Context) (string, error) {
+	revisions := make([]string, 0, len(s.layers))
+	known := true
+	for _, layer := range s.layers {
+		revision, err := layer.Source.Revision(ctx)
+		if err != nil {
+			return "", fmt.Errorf("layer %s: %w", layer.Name, err)
+		}
+		known = known && revision != ""
+		revisions = append(revisions, layer.Name+"@"+revision)
+	}
+
+	s.mtx.Lock()
+	s.provenance = make(map[string]map[string]string)
+	s.mtx.Unlock()
+
+	if !known {
+		return "", nil
+	}
+	return strings.Join(revisions, ","), nil
+}
+
+// Chains returns the chains of all layers
+func (s *LayeredSource) Chains(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	chains := make([]string, 0)
+	for _, layer := range s.layers {
+		layerChains, err := layer.Source.Chains(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", layer.Name, err)
+		}
+		for _, chain := range layerChains {
+			if !seen[chain] {
+				seen[chain] = true
+				chains = append(chains, chain)
+			}
+		}
+	}
+	sort.Strings(chains)
+	return chains, nil
+}
+
+// ReadFile merges the file from every layer that has it
+func (s *LayeredSource) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	var (
+		merged     interface{}
+		found      bool
+		provenance = make(map[string]string)
+	)
+	for _, layer := range s.layers {
+		data, err := layer.Source.ReadFile(ctx, path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", layer.Name, err)
+		}
+
+		var value interface{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("layer %s: %s: %w", layer.Name, path, err)
+		}
+		merged = mergeJSON(merged, value, "", layer.Name, provenance)
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: %w", path, fs.ErrNotExist)
+	}
+
+	s.mtx.Lock()
+	s.provenance[path] = provenance
+	s.mtx.Unlock()
+	return json.Marshal(merged)
+}
+
+// Provenance returns which layer each field of the file came from the last
+// time it was read
+func (s *LayeredSource) Provenance(path string) map[string]string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.provenance[path]
+}
+
+func (s *LayeredSource) useMetrics(m *metrics) {
+	for _, layer := range s.layers {
+		if source, ok := layer.Source.(metricsUser); ok {
+			source.useMetrics(m)
+		}
+	}
+}
+
+// mergeJSON merges overlay into base, recording the layer of every value it
+// sets in provenance
+func mergeJSON(base, overlay interface{}, path, layer string, provenance map[string]string) interface{} {
+	switch overlay := overlay.(type) {
+	case map[string]interface{}:
+		baseObj, ok := base.(map[string]interface{})
+		if !ok {
+			clearProvenance(provenance, path, base)
+			baseObj = make(map[string]interface{}, len(overlay))
+		}
+		for key, value := range overlay {
+			child := joinPath(path, key)
+			if value == nil {
+				clearProvenance(provenance, child, baseObj[key])
+				delete(baseObj, key)
+				continue
+			}
+			baseObj[key] = mergeJSON(baseObj[key], value, child, layer, provenance)
+		}
+		return baseObj
+	case []interface{}:
+		if key, ok := keyedArrays[path]; ok {
+			return mergeKeyedArray(base, overlay, key, path, layer, provenance)
+		}
+	}
+	clearProvenance(provenance, path, base)
+	provenance[path] = layer
+	return overlay
+}
+
+// mergeKeyedArray merges the elements of overlay into base by their key
+// field. Elements without the key are appended.
+func mergeKeyedArray(base interface{}, overlay []interface{}, key, path, layer string, provenance map[string]string) interface{} {
+	baseArr, _ := base.([]interface{})
+	index := make(map[string]int, len(baseArr))
+	for idx, elem := range baseArr {
+		if id, ok := elementKey(elem, key); ok {
+			index[id] = idx
+		}
+	}
+	for _, elem := range overlay {
+		id, ok := elementKey(elem, key)
+		if !ok {
+			baseArr = append(baseArr, elem)
+			provenance[joinPath(path, fmt.Sprint(len(baseArr)-1))] = layer
+			continue
+		}
+		child := joinPath(path, id)
+		if idx, exists := index[id]; exists {
+			baseArr[idx] = mergeJSON(baseArr[idx], elem, child, layer, provenance)
+			continue
+		}
+		index[id] = len(baseArr)
+		baseArr = append(baseArr, mergeJSON(nil, elem, child, layer, provenance))
+	}
+	return baseArr
+}
+
+func elementKey(elem interface{}, key string) (string, bool) {
+	obj, ok := elem.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	id, ok := obj[key].(string)
+	return id, ok
+}
+
+// clearProvenance removes the provenance of a value at path that is being
+// replaced, including everything below it
+func clearProvenance(provenance map[string]string, path string, value interface{}) {
+	delete(provenance, path)
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		// nothing below a scalar
+		return
+	}
+	for field := range provenance {
+		if path == "" || strings.HasPrefix(field, path+".") {
+			delete(provenance, field)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func decodeTestJSON(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader([]byte(data)))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestMergeJSON(t *testing.T) {
+	testCases := []struct {
+		name       string
+		layers     []string // upstream first, then local and so on
+		merged     string
+		provenance map[string]string
+	}{
+		{
+			"override a field",
+			[]string{`{"a":1,"b":2}`, `{"b":3}`},
+			`{"a":1,"b":3}`,
+			map[string]string{"a": "upstream", "b": "local"},
+		},
+		{
+			"add a field",
+			[]string{`{"a":1}`, `{"b":2}`},
+			`{"a":1,"b":2}`,
+			map[string]string{"a": "upstream", "b": "local"},
+		},
+		{
+			"null deletes a field",
+			[]string{`{"a":1,"b":{"c":1,"d":2}}`, `{"b":null}`},
+			`{"a":1}`,
+			map[string]string{"a": "upstream"},
+		},
+		{
+			"null deletes a nested field",
+			[]string{`{"a":{"b":1,"c":2}}`, `{"a":{"c":null}}`},
+			`{"a":{"b":1}}`,
+			map[string]string{"a.b": "upstream"},
+		},
+		{
+			"null of a missing field",
+			[]string{`{"a":1}`, `{"b":null}`},
+			`{"a":1}`,
+			map[string]string{"a": "upstream"},
+		},
+		{
+			"null in a new object",
+			[]string{`{}`, `{"a":{"b":null,"c":1}}`},
+			`{"a":{"c":1}}`,
+			map[string]string{"a.c": "local"},
+		},
+		{
+			"arrays are replaced",
+			[]string{`{"tags":["x","y"]}`, `{"tags":["z"]}`},
+			`{"tags":["z"]}`,
+			map[string]string{"tags": "local"},
+		},
+		{
+			"nested objects are merged",
+			[]string{
+				`{"apis":{"rpc":[{"address":"a"}],"rest":[{"address":"b"}]}}`,
+				`{"apis":{"rest":[]}}`,
+			},
+			`{"apis":{"rpc":[{"address":"a"}],"rest":[]}}`,
+			map[string]string{"apis.rpc": "upstream", "apis.rest": "local"},
+		},
+		{
+			"object replaced by a scalar",
+			[]string{`{"a":{"b":1,"c":{"d":2}}}`, `{"a":"x"}`},
+			`{"a":"x"}`,
+			map[string]string{"a": "local"},
+		},
+		{
+			"scalar replaced by an object",
+			[]string{`{"a":1}`, `{"a":{"b":1}}`},
+			`{"a":{"b":1}}`,
+			map[string]string{"a.b": "local"},
+		},
+		{
+			"assets are merged by base denom",
+			[]string{
+				`{"assets":[{"base":"uatom","name":"Atom","description":"d"}]}`,
+				`{"assets":[{"base":"uatom","name":"ATOM"},{"base":"uosmo","name":"Osmo"},{"name":"no base"}]}`,
+			},
+			`{"assets":[{"base":"uatom","name":"ATOM","description":"d"},{"base":"uosmo","name":"Osmo"},{"name":"no base"}]}`,
+			map[string]string{
+				"assets.uatom.base":        "local",
+				"assets.uatom.name":        "local",
+				"assets.uatom.description": "upstream",
+				"assets.uosmo.base":        "local",
+				"assets.uosmo.name":        "local",
+				"assets.2":                 "local",
+			},
+		},
+		{
+			"asset field deleted",
+			[]string{
+				`{"assets":[{"base":"uatom","name":"Atom","description":"d"}]}`,
+				`{"assets":[{"base":"uatom","description":null}]}`,
+			},
+			`{"assets":[{"base":"uatom","name":"Atom"}]}`,
+			map[string]string{"assets.uatom.base": "local", "assets.uatom.name": "upstream"},
+		},
+		{
+			"three layers",
+			[]string{`{"a":1,"b":1,"c":1}`, `{"b":2,"c":2}`, `{"c":3}`},
+			`{"a":1,"b":2,"c":3}`,
+			map[string]string{"a": "upstream", "b": "local", "c": "ops"},
+		},
+		{
+			"document replaced by a scalar",
+			[]string{`{"a":1}`, `"x"`},
+			`"x"`,
+			map[string]string{"": "local"},
+		},
+	}
+	names := []string{"upstream", "local", "ops"}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var merged interface{}
+			provenance := make(map[string]string)
+			for idx, layer := range tc.layers {
+				merged = mergeJSON(merged, decodeTestJSON(t, layer), "", names[idx], provenance)
+			}
+			if expected := decodeTestJSON(t, tc.merged); !reflect.DeepEqual(merged, expected) {
+				got, _ := json.Marshal(merged)
+				t.Fatalf("expected %s, got %s", tc.merged, got)
+			}
+			if !reflect.DeepEqual(provenance, tc.provenance) {
+				t.Fatalf("expected provenance %v, got %v", tc.provenance, provenance)
+			}
+		})
+	}
+}
+
+func TestLayeredSource(t *testing.T) {
+	upstream := &testSource{revision: "a", files: map[string]string{
+		"cosmoshub/chain.json": `{"chain_name":"cosmoshub","pretty_name":"Cosmos","status":"live"}`,
+		"osmosis/chain.json":   `{"chain_name":"osmosis"}`,
+	}}
+	local := &testSource{revision: "b", files: map[string]string{
+		"cosmoshub/chain.json": `{"pretty_name":"Cosmos Hub","status":null}`,
+		"newchain/chain.json":  `{"chain_name":"newchain"}`,
+	}}
+	source := NewLayeredSource(Layer{Name: "upstream", Source: upstream}, Layer{Name: "local", Source: local})
+	ctx := context.Background()
+
+	revision, err := source.Revision(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision != "upstream@a,local@b" {
+		t.Fatalf("unexpected revision %s", revision)
+	}
+	chains, err := source.Chains(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(chains, []string{"cosmoshub", "newchain", "osmosis"}) {
+		t.Fatalf("unexpected chains %v", chains)
+	}
+
+	data, err := source.ReadFile(ctx, "cosmoshub/chain.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := decodeTestJSON(t, `{"chain_name":"cosmoshub","pretty_name":"Cosmos Hub"}`); !reflect.DeepEqual(decodeTestJSON(t, string(data)), expected) {
+		t.Fatalf("unexpected merged file %s", data)
+	}
+	provenance := map[string]string{"chain_name": "upstream", "pretty_name": "local"}
+	if got := source.Provenance("cosmoshub/chain.json"); !reflect.DeepEqual(got, provenance) {
+		t.Fatalf("expected provenance %v, got %v", provenance, got)
+	}
+	if _, err := source.ReadFile(ctx, "cosmoshub/assetlist.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected a missing file, got %v", err)
+	}
+
+	// an unknown revision of any layer makes the combined revision unknown
+	local.set("", local.files)
+	if revision, err := source.Revision(ctx); err != nil || revision != "" {
+		t.Fatalf("expected an unknown revision, got %q, %v", revision, err)
+	}
+	if got := source.Provenance("cosmoshub/chain.json"); got != nil {
+		t.Fatalf("expected provenance to be reset by Revision, got %v", got)
+	}
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// maxListLimit bounds the number of items in a page
+const maxListLimit = 1000
+
+// listQuery holds the parameters of a request to a list endpoint
+type listQuery struct {
+	limit  int
+	cursor *listCursor
+	sort   string
+	expand bool
+	fields []string
+}
+
+// listCursor is the position after the last item of a page. It is encoded
+// as an opaque string.
+type listCursor struct {
+	Key  string `json:"k"`
+	Name string `json:"n"`
+}
+
+func (c listCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*listCursor, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, false
+	}
+	return &cursor, true
+}
+
+// listItem is an item of a list endpoint with the key it is sorted by
+type listItem struct {
+	name string
+	key  string
+}
+
+// isListQuery reports whether the request uses any of the list parameters.
+// Requests that don't are answered with the plain list of names.
+func isListQuery(query url.Values) bool {
+	for _, param := range []string{"limit", "cursor", "sort", "expand", "fields"} {
+		if query.Has(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseListQuery parses the limit, cursor, sort, expand and fields
+// parameters. sorts lists the allowed sort orders, the first being the
+// default.
+func parseListQuery(res http.ResponseWriter, query url.Values, sorts ...string) (listQuery, bool) {
+	q := listQuery{sort: sorts[0]}
+	if param := query.Get("limit"); param != "" {
+		limit, err := strconv.Atoi(param)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			badRequest(res, "invalid limit %q, expected 1 to %d", param, maxListLimit)
+			return q, false
+		}
+		q.limit = limit
+	}
+	if param := query.Get("cursor"); param != "" {
+		cursor, ok := decodeCursor(param)
+		if !ok {
+			badRequest(res, "invalid cursor")
+			return q, false
+		}
+		q.cursor = cursor
+	}
+	if param := query.Get("sort"); param != "" {
+		valid := false
+		for _, sort := range sorts {
+			valid = valid || param == sort
+		}
+		if !valid {
+			badRequest(res, "invalid sort %q, expected one of %s", param, strings.Join(sorts, ", "))
+			return q, false
+		}
+		q.sort = param
+	}
+	if param := query.Get("expand"); param != "" {
+		expand, err := strconv.ParseBool(param)
+		if err != nil {
+			badRequest(res, "invalid expand %q", param)
+			return q, false
+		}
+		q.expand = expand
+	}
+	if param := query.Get("fields"); param != "" {
+		for _, field := range strings.Split(param, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				q.fields = append(q.fields, field)
+			}
+		}
+		// selecting fields only makes sense for full objects
+		q.expand = true
+	}
+	return q, true
+}
+
+// page sorts the items and returns those in the page selected by the query
+// along with the cursor of the next page, if there is one
+func (q listQuery) page(items []listItem) ([]listItem, string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].key != items[j].key {
+			return items[i].key < items[j].key
+		}
+		return items[i].name < items[j].name
+	})
+	start := 0
+	if q.cursor != nil {
+		// the page starts after the cursor even if the item it points to has
+		// since been removed
+		start = sort.Search(len(items), func(i int) bool {
+			if items[i].key != q.cursor.Key {
+				return items[i].key > q.cursor.Key
+			}
+			return items[i].name > q.cursor.Name
+		})
+	}
+	end := len(items)
+	if q.limit > 0 && start+q.limit < end {
+		end = start + q.limit
+	}
+	page := items[start:end]
+	if end == len(items) || len(page) == 0 {
+		return page, ""
+	}
+	last := page[len(page)-1]
+	return page, listCursor{Key: last.key, Name: last.name}.encode()
+}
+
+// selectFields returns the fields of v at the given dot separated paths,
+// i.e. "apis.rpc". Paths through arrays select the field of every element.
+// Paths within another selected path, such as "apis.rpc" with "apis", are
+// already covered by it. A nil v selects nothing and returns nil.
+func selectFields(v interface{}, fields []string) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	selected := make(map[string]interface{})
+	for _, field := range fields {
+		if !coveredField(field, fields) {
+			selectPath(obj, selected, strings.Split(field, "."))
+		}
+	}
+	return selected, nil
+}
+
+// coveredField reports whether another of the fields is a parent of field
+func coveredField(field string, fields []string) bool {
+	for _, parent := range fields {
+		if strings.HasPrefix(field, parent+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func selectPath(src, dst map[string]interface{}, path []string) {
+	value, ok := src[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		child, ok := dst[path[0]].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			dst[path[0]] = child
+		}
+		selectPath(value, child, path[1:])
+	case []interface{}:
+		elems, ok := dst[path[0]].([]interface{})
+		if !ok {
+			elems = make([]interface{}, len(value))
+			dst[path[0]] = elems
+		}
+		for idx, elem := range value {
+			elem, ok := elem.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			child, ok := elems[idx].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				elems[idx] = child
+			}
+			selectPath(elem, child, path[1:])
+		}
+	}
+}
+
+// respondWithList writes a page of items. Items are the names unless the
+// query expands them, in which case object returns the full object of a name.
+func respondWithList(res http.ResponseWriter, q listQuery, items []listItem, object func(name string) interface{}) {
+	page, next := q.page(items)
+	resp := types.ListResponse{NextCursor: next, Total: len(items)}
+	if !q.expand {
+		names := make([]string, len(page))
+		for idx, item := range page {
+			names[idx] = item.name
+		}
+		resp.Items = names
+		respondWithJSON(res, resp)
+		return
+	}
+
+	objects := make([]interface{}, len(page))
+	for idx, item := range page {
+		objects[idx] = object(item.name)
+		if len(q.fields) > 0 {
+			selected, err := selectFields(objects[idx], q.fields)
+			if err != nil {
+				internalError(res)
+				return
+			}
+			objects[idx] = selected
+		}
+	}
+	resp.Items = objects
+	respondWithJSON(res, resp)
+}
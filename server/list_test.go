@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// listPage is a page of names returned by a list endpoint
+type listPage struct {
+	Items      []string `json:"items"`
+	NextCursor string   `json:"next_cursor"`
+	Total      int      `json:"total"`
+}
+
+func listChains(t *testing.T, router http.Handler, query url.Values) (int, listPage) {
+	t.Helper()
+	rec := serveTest(router, http.MethodGet, "/v1/chains?"+query.Encode())
+	var page listPage
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Code, page
+}
+
+func TestListCursor(t *testing.T) {
+	chainFiles := func(names ...string) map[string]string {
+		files := make(map[string]string)
+		for _, name := range names {
+			files[name+"/chain.json"] = `{"chain_name":"` + name + `","chain_id":"` + name + `-1"}`
+		}
+		return files
+	}
+	h, source, router := newTestHandler(t, chainFiles("akash", "cosmoshub", "juno", "osmosis"))
+
+	// walk all pages
+	var names []string
+	query := url.Values{"limit": {"3"}}
+	for {
+		code, page := listChains(t, router, query)
+		if code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", code)
+		}
+		if page.Total != 4 {
+			t.Fatalf("expected a total of 4, got %d", page.Total)
+		}
+		names = append(names, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+	if expected := []string{"akash", "cosmoshub", "juno", "osmosis"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+
+	// a cursor at the last item returns an empty last page
+	code, page := listChains(t, router, url.Values{"cursor": {listCursor{Key: "osmosis", Name: "osmosis"}.encode()}})
+	if code != http.StatusOK || len(page.Items) != 0 || page.NextCursor != "" {
+		t.Fatalf("expected an empty last page, got %d %+v", code, page)
+	}
+	// as does a cursor past the end
+	code, page = listChains(t, router, url.Values{"cursor": {listCursor{Key: "zzz", Name: "zzz"}.encode()}})
+	if code != http.StatusOK || len(page.Items) != 0 || page.NextCursor != "" {
+		t.Fatalf("expected an empty last page, got %d %+v", code, page)
+	}
+
+	// the cursor of the first page still applies once the item it points to
+	// has been removed and another added before it
+	_, first := listChains(t, router, url.Values{"limit": {"2"}})
+	if !reflect.DeepEqual(first.Items, []string{"akash", "cosmoshub"}) {
+		t.Fatalf("unexpected first page %v", first.Items)
+	}
+	source.set("2", chainFiles("akash", "agoric", "juno", "osmosis", "stargaze"))
+	if err := h.Pull(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	code, page = listChains(t, router, url.Values{"limit": {"2"}, "cursor": {first.NextCursor}})
+	if code != http.StatusOK || !reflect.DeepEqual(page.Items, []string{"juno", "osmosis"}) || page.NextCursor == "" {
+		t.Fatalf("expected [juno osmosis] with a next page, got %d %+v", code, page)
+	}
+	if page.Total != 5 {
+		t.Fatalf("expected the total of the new snapshot, got %d", page.Total)
+	}
+
+	// a cursor keeps its position in the sort order it was issued for
+	_, byID := listChains(t, router, url.Values{"limit": {"1"}, "sort": {"chain_id"}})
+	code, page = listChains(t, router, url.Values{"limit": {"1"}, "sort": {"chain_id"}, "cursor": {byID.NextCursor}})
+	if code != http.StatusOK || !reflect.DeepEqual(page.Items, []string{"akash"}) {
+		t.Fatalf("expected [akash], got %d %+v", code, page)
+	}
+
+	// tampered cursors are rejected
+	for _, cursor := range []string{
+		"not a cursor!",
+		base64.StdEncoding.EncodeToString([]byte(`{"k":"a","n":"a"}`)) + "==",
+		base64.RawURLEncoding.EncodeToString([]byte(`{"k":`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`["a","a"]`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"k":1}`)),
+	} {
+		if code, _ := listChains(t, router, url.Values{"cursor": {cursor}}); code != http.StatusBadRequest {
+			t.Fatalf("expected cursor %q to be rejected, got %d", cursor, code)
+		}
+	}
+}
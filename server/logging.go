@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// RequestIDHeader is the header used to propagate request IDs. Incoming IDs
+// are reused, otherwise one is generated.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// NewLogger creates a structured logger writing either "json" or "logfmt"
+// formatted records at or above the given level ("debug", "info", "warn" or
+// "error")
+func NewLogger(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "logfmt", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected json or logfmt", format)
+	}
+}
+
+// accessLog assigns each request an ID and logs its method, path, status and
+// latency once it has been served
+func accessLog(log *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		id := req.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		res.Header().Set(RequestIDHeader, id)
+		req = req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id))
+
+		rec := &statusRecorder{ResponseWriter: res, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+
+		log.LogAttrs(req.Context(), slog.LevelInfo, "request",
+			slog.String("request_id", id),
+			slog.String("method", req.Method),
+			slog.String("path", redactedURI(req.URL)),
+			slog.Int("status", rec.status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote", req.RemoteAddr),
+		)
+	})
+}
+
+// recoverPanics responds with an internal error if a handler panics, logging
+// the panic with its stack. Responses that were already started are aborted.
+func recoverPanics(log *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		w := &startedRecorder{ResponseWriter: res}
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			id, _ := req.Context().Value(requestIDKey{}).(string)
+			log.Error("handler panicked", "request_id", id, "panic", err, "stack", string(debug.Stack()))
+			if w.started {
+				panic(http.ErrAbortHandler)
+			}
+			// drop headers that described the response that was being written
+			header := res.Header()
+			for _, name := range []string{"Content-Encoding", "Content-Length", "ETag", "Last-Modified"} {
+				header.Del(name)
+			}
+			header.Set("Cache-Control", "no-store")
+			internalError(res)
+		}()
+		next.ServeHTTP(w, req)
+	})
+}
+
+// startedRecorder records whether a response has been started
+type startedRecorder struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (r *startedRecorder) WriteHeader(status int) {
+	r.started = true
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *startedRecorder) Write(b []byte) (int, error) {
+	r.started = true
+	return r.ResponseWriter.Write(b)
+}
+
+// redactedURI returns the path and query of a url with any API key in the
+// query redacted
+func redactedURI(u *url.URL) string {
+	query := u.Query()
+	if !query.Has("api_key") {
+		return u.RequestURI()
+	}
+	query.Set("api_key", "REDACTED")
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.RequestURI()
+}
+
+// requestLog returns the handler's logger annotated with the request's ID
+func (h Handler) requestLog(req *http.Request) *slog.Logger {
+	if id, ok := req.Context().Value(requestIDKey{}).(string); ok {
+		return h.log.With("request_id", id)
+	}
+	return h.log
+}
+
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// cronLogger adapts a structured logger to the cron scheduler
+type cronLogger struct {
+	log *slog.Logger
+}
+
+func (l cronLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log.Debug(msg, keysAndValues...)
+}
+
+func (l cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.log.Error(msg, append([]interface{}{"err", err}, keysAndValues...)...)
+}
@@ -0,0 +1,279 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	requestBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	pullBuckets    = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200}
+)
+
+// metrics records server and ingestion metrics and exposes them in the
+// Prometheus text format. It is safe for concurrent use.
+type metrics struct {
+	mtx sync.Mutex
+
+	requests        map[requestKey]uint64 // route, method and status -> count
+	requestDuration map[string]*histogram // route -> latency
+	pullDuration    *histogram
+	pulls           map[string]uint64 // result -> count
+	parseErrors     map[string]uint64 // chain -> count
+	chains          int
+	assets          int
+	failingChains   int
+	lastUpdated     time.Time
+	// the number of requests to the github api remaining in the current rate
+	// limit window. Negative if unknown.
+	rateLimitRemaining int
+}
+
+type requestKey struct {
+	route, method string
+	code          int
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:           make(map[requestKey]uint64),
+		requestDuration:    make(map[string]*histogram),
+		pullDuration:       newHistogram(pullBuckets),
+		pulls:              make(map[string]uint64),
+		parseErrors:        make(map[string]uint64),
+		lastUpdated:        time.Unix(0, 0),
+		rateLimitRemaining: -1,
+	}
+}
+
+func (m *metrics) observeRequest(route, method string, code int, duration time.Duration) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.requests[requestKey{route: route, method: method, code: code}]++
+	h, ok := m.requestDuration[route]
+	if !ok {
+		h = newHistogram(requestBuckets)
+		m.requestDuration[route] = h
+	}
+	h.observe(duration.Seconds())
+}
+
+func (m *metrics) observePull(duration time.Duration, err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.pullDuration.observe(duration.Seconds())
+	if err != nil {
+		m.pulls["failure"]++
+	} else {
+		m.pulls["success"]++
+	}
+}
+
+func (m *metrics) setRegistry(chains, assets int, lastUpdated time.Time) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.chains, m.assets, m.lastUpdated = chains, assets, lastUpdated
+}
+
+func (m *metrics) setFailingChains(count int) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.failingChains = count
+}
+
+func (m *metrics) parseError(chain string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.parseErrors[chain]++
+}
+
+// observeRateLimit records the remaining github api rate limit from the
+// headers of a response
+func (m *metrics) observeRateLimit(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.rateLimitRemaining = remaining
+}
+
+// middleware records the count and latency of requests by the route of router
+// that they match. It wraps the whole handler chain so that requests which
+// never reach a route, i.e. unknown paths or rejected keys, are counted too.
+func (m *metrics) middleware(router *mux.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: res, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+
+		route := "unknown"
+		var match mux.RouteMatch
+		if router.Match(req, &match) && match.Route != nil {
+			if tmpl, err := match.Route.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+		m.observeRequest(route, methodLabel(req.Method), rec.status, time.Since(start))
+	})
+}
+
+// methodLabel returns the method label of a request. Non standard methods are
+// all labelled "other" so that clients can't create arbitrarily many series.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "other"
+	}
+}
+
+// ServeHTTP writes all metrics in the Prometheus text exposition format
+func (m *metrics) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	res.WriteHeader(http.StatusOK)
+	m.write(res)
+}
+
+func (m *metrics) write(w io.Writer) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	writeHeader(w, "skychart_http_requests_total", "counter", "Total number of HTTP requests by route, method and status code.")
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "skychart_http_requests_total{route=%s,method=%s,code=\"%d\"} %d\n",
+			quoteLabel(key.route), quoteLabel(key.method), key.code, m.requests[key])
+	}
+
+	writeHeader(w, "skychart_http_request_duration_seconds", "histogram", "Latency of HTTP requests by route.")
+	for _, route := range sortedKeys(m.requestDuration) {
+		m.requestDuration[route].write(w, "skychart_http_request_duration_seconds", "route="+quoteLabel(route))
+	}
+
+	writeHeader(w, "skychart_pull_duration_seconds", "histogram", "Duration of registry pulls.")
+	m.pullDuration.write(w, "skychart_pull_duration_seconds", "")
+
+	writeHeader(w, "skychart_pulls_total", "counter", "Total number of registry pulls by result.")
+	for _, result := range []string{"success", "failure"} {
+		fmt.Fprintf(w, "skychart_pulls_total{result=%q} %d\n", result, m.pulls[result])
+	}
+
+	writeHeader(w, "skychart_chains", "gauge", "Number of chains loaded from the registry.")
+	fmt.Fprintf(w, "skychart_chains %d\n", m.chains)
+
+	writeHeader(w, "skychart_assets", "gauge", "Number of assets loaded from the registry.")
+	fmt.Fprintf(w, "skychart_assets %d\n", m.assets)
+
+	writeHeader(w, "skychart_chains_failing", "gauge", "Number of chains that failed to be pulled in the last update and are served stale or not at all.")
+	fmt.Fprintf(w, "skychart_chains_failing %d\n", m.failingChains)
+
+	writeHeader(w, "skychart_last_update_timestamp_seconds", "gauge", "Unix time of the last successful registry update.")
+	fmt.Fprintf(w, "skychart_last_update_timestamp_seconds %d\n", m.lastUpdated.Unix())
+
+	writeHeader(w, "skychart_last_update_age_seconds", "gauge", "Seconds since the last successful registry update.")
+	fmt.Fprintf(w, "skychart_last_update_age_seconds %s\n", formatFloat(time.Since(m.lastUpdated).Seconds()))
+
+	writeHeader(w, "skychart_github_rate_limit_remaining", "gauge", "Requests remaining in the current GitHub API rate limit window. -1 if unknown.")
+	fmt.Fprintf(w, "skychart_github_rate_limit_remaining %d\n", m.rateLimitRemaining)
+
+	writeHeader(w, "skychart_chain_parse_errors_total", "counter", "Total number of errors parsing a chain's registry files.")
+	for _, chain := range sortedKeys(m.parseErrors) {
+		fmt.Fprintf(w, "skychart_chain_parse_errors_total{chain=%s} %d\n", quoteLabel(chain), m.parseErrors[chain])
+	}
+}
+
+// histogram is a cumulative Prometheus histogram. It is not safe for
+// concurrent use on its own.
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(value float64) {
+	for idx, bound := range h.buckets {
+		if value <= bound {
+			h.counts[idx]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+func (h *histogram) write(w io.Writer, name, labels string) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	for idx, bound := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, sep, formatFloat(bound), h.counts[idx])
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, h.count)
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func quoteLabel(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return `"` + value + `"`
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
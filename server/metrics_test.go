@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMetricsMethodLabel(t *testing.T) {
+	h, _, router := newTestHandler(t, map[string]string{
+		"cosmoshub/chain.json": `{"chain_name":"cosmoshub","chain_id":"cosmoshub-4"}`,
+	})
+	root := h.metrics.middleware(router, router)
+	for _, method := range []string{http.MethodGet, "FOO1", "FOO2", "get"} {
+		serveTest(root, method, "/v1/chains")
+	}
+
+	h.metrics.mtx.Lock()
+	defer h.metrics.mtx.Unlock()
+	methods := make(map[string]uint64)
+	for key, count := range h.metrics.requests {
+		methods[key.method] += count
+	}
+	if len(methods) != 2 || methods[http.MethodGet] != 1 || methods["other"] != 3 {
+		t.Fatalf("expected 1 GET and 3 other requests, got %v", methods)
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Option configures optional behaviour of the Handler and server
+type Option func(*options)
+
+type options struct {
+	// directory used to cache genesis files. If empty, genesis files are not
+	// served.
+	genesisDir string
+	// logger used by the server. Defaults to slog.Default()
+	logger *slog.Logger
+	// how long to wait for in-flight requests to complete on shutdown
+	shutdownTimeout time.Duration
+	// the number of chains fetched in parallel during a pull
+	concurrency int
+	// timeout of each request to the registry during a pull
+	requestTimeout time.Duration
+	// where the registry is read from. Defaults to the GitHub API.
+	source Source
+	// directory that local overrides are persisted in. If empty, the admin
+	// API is disabled.
+	overridesDir string
+	// an API key with admin scope, used for the admin API
+	adminToken string
+	// API keys and rate limits. By default there are no limits.
+	auth AuthConfig
+	// which cross-origin requests are allowed. By default any origin is.
+	cors CORSConfig
+	// how long clients and CDNs may cache responses without revalidating
+	cacheMaxAge time.Duration
+}
+
+func defaultOptions() options {
+	return options{
+		shutdownTimeout: 10 * time.Second,
+		concurrency:     8,
+		requestTimeout:  30 * time.Second,
+		cacheMaxAge:     time.Minute,
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.logger == nil {
+		o.logger = slog.Default()
+	}
+	return o
+}
+
+// WithGenesisCache enables fetching, caching and serving genesis files from
+// the provided directory
+func WithGenesisCache(dir string) Option {
+	return func(o *options) {
+		o.genesisDir = dir
+	}
+}
+
+// WithLogger sets the structured logger used for server, access and ingestion
+// logs
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithShutdownTimeout sets how long the server waits for in-flight requests to
+// complete once the context passed to Serve is cancelled. Requests still
+// running after the timeout are dropped.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// WithConcurrency sets the number of chains that are fetched in parallel when
+// pulling the registry
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithRequestTimeout sets the timeout of each request made to the GitHub API
+// when pulling
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.requestTimeout = timeout
+		}
+	}
+}
+
+// WithSource sets where the registry is read from instead of the GitHub
+// repository given by the registry url
+func WithSource(source Source) Option {
+	return func(o *options) {
+		o.source = source
+	}
+}
+
+// WithOverrides enables the admin API for local overrides of chains and asset
+// lists, persisting them in dir. Requests to the admin API must carry an API
+// key with admin scope. If token is not empty it is added as such a key.
+func WithOverrides(dir, token string) Option {
+	return func(o *options) {
+		o.overridesDir = dir
+		o.adminToken = token
+	}
+}
+
+// WithAuth configures API keys, scopes and rate limits
+func WithAuth(cfg AuthConfig) Option {
+	return func(o *options) {
+		o.auth = cfg
+	}
+}
+
+// WithCORS sets which cross-origin requests browsers are allowed to make
+func WithCORS(cfg CORSConfig) Option {
+	return func(o *options) {
+		o.cors = cfg
+	}
+}
+
+// WithCacheMaxAge sets how long clients and CDNs may cache responses before
+// revalidating them with their ETag
+func WithCacheMaxAge(maxAge time.Duration) Option {
+	return func(o *options) {
+		if maxAge >= 0 {
+			o.cacheMaxAge = maxAge
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// overrideFiles are the registry files of a chain that can be overridden
+var overrideFiles = map[string]bool{"chain.json": true, "assetlist.json": true}
+
+// overrideStore persists local overrides of chain.json and assetlist.json
+// files in a directory laid out like the registry. It is used as the highest
+// priority layer when merging registries.
+type overrideStore struct {
+	dir string
+	mtx sync.RWMutex
+}
+
+var _ Source = (*overrideStore)(nil)
+
+func newOverrideStore(dir string) *overrideStore {
+	return &overrideStore{dir: dir}
+}
+
+func (s *overrideStore) String() string {
+	return "overrides:" + s.dir
+}
+
+// Revision returns a hash of all overrides so that any change to them causes
+// the registry to be pulled again
+func (s *overrideStore) Revision(ctx context.Context) (string, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	chains, err := s.chains()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	for _, chain := range chains {
+		for _, file := range []string{"chain.json", "assetlist.json"} {
+			data, err := os.ReadFile(filepath.Join(s.dir, chain, file))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(hash, "%s/%s:%d:", chain, file, len(data))
+			hash.Write(data)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Chains lists the chains that have overrides
+func (s *overrideStore) Chains(ctx context.Context) ([]string, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.chains()
+}
+
+func (s *overrideStore) chains() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	chains := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && isChainDir(entry.Name()) {
+			chains = append(chains, entry.Name())
+		}
+	}
+	sort.Strings(chains)
+	return chains, nil
+}
+
+// ReadFile reads an override
+func (s *overrideStore) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	chain, file := filepath.Split(filepath.FromSlash(path))
+	chain = filepath.Clean(chain)
+	if !validChainName(chain) || !overrideFiles[file] {
+		return nil, fmt.Errorf("%s: %w", path, fs.ErrNotExist)
+	}
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return os.ReadFile(filepath.Join(s.dir, chain, file))
+}
+
+// write atomically replaces an override
+func (s *overrideStore) write(chain, file string, data []byte) error {
+	if !validChainName(chain) || !overrideFiles[file] {
+		return fmt.Errorf("invalid override %s/%s", chain, file)
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	dir := filepath.Join(s.dir, chain)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+file+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, file))
+}
+
+// remove deletes an override, returning false if it didn't exist
+func (s *overrideStore) remove(chain, file string) (bool, error) {
+	if !validChainName(chain) || !overrideFiles[file] {
+		return false, nil
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	dir := filepath.Join(s.dir, chain)
+	err := os.Remove(filepath.Join(dir, file))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// clean up the chain's directory once it has no overrides left
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+		_ = os.Remove(dir)
+	}
+	return true, nil
+}
+
+// validChainName reports whether name can safely be used as a directory
+func validChainName(name string) bool {
+	if name == "" || name == "." || name == ".." || !isChainDir(name) {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
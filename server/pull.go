@@ -3,199 +3,233 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"strings"
+	"io/fs"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/cmwaters/skychart/types"
 )
 
-// Pull requests all registry information from a github repo and updates the
+// Pull requests all registry information from the handler's source and updates the
 // handlers local registry. It expects a directory structure as follows:
 // - [chain_name]
 //   - chain.json
 //   - assetlist.json
-// It works on a best effort basis. All chain names should be unique. chain.json and
+//
+// It works on a best effort basis: chains whose files fail to be fetched or parsed keep
+// their previously pulled version and the error is reported through the status endpoint.
+// All chain names should be unique. chain.json and
 // assetlist.json should comply with the respective schemas
 // TODO: Add support for relayer paths
-func (h *Handler) Pull(ctx context.Context) error {
-	// If there have been no recent commits we can return immediately
-	recent, err := h.recentCommits()
+func (h *Handler) Pull(ctx context.Context) (err error) {
+	h.pulling.Lock()
+	defer h.pulling.Unlock()
+
+	start := time.Now()
+	defer func() {
+		h.metrics.observePull(time.Since(start), err)
+		h.status.pulled(err)
+	}()
+
+	current := h.snapshot()
+
+	// If the registry hasn't changed we can return immediately
+	revision, err := h.source.Revision(ctx)
 	if err != nil {
 		return err
 	}
-	if !recent {
-		h.log.Printf("no new recent commits since %s", h.lastUpdated.String())
-		h.lastUpdated = time.Now()
+	// Chains that failed to be pulled last time are retried even if there
+	// are no new commits
+	if revision != "" && revision == current.commit && len(current.chainErrors) == 0 {
+		h.log.Info("no new commits", "commit", revision, "since", current.lastUpdated)
+		next := *current
+		next.lastUpdated = time.Now()
+		h.current.Store(&next)
+		h.metrics.setRegistry(len(next.chains), len(next.assets), next.lastUpdated)
+		h.status.updated(revision, next.lastUpdated, len(next.chains), len(next.assets))
 		return nil
 	}
 
+	// The new snapshot is built up separately and only swapped in once
+	// complete. If the pull fails or is cancelled part way through, the
+	// current snapshot is kept and the next pull starts over.
+	next := newRegistry()
+	commit := revision
+
 	// update chains
-	if err := h.getChains(); err != nil {
+	chains, err := h.source.Chains(ctx)
+	if err != nil {
 		return err
 	}
+	next.chains = chains
+	h.log.Info("pulling registry", "commit", commit, "chains", len(chains))
 
-	// for each chain update the chain info and asset list
+	// for each chain update the chain info and asset list. Chains are
+	// fetched in parallel by a bounded pool of workers but applied in order
+	// so the result is deterministic. A chain that fails to be pulled keeps
+	// the version from the current snapshot so that a single bad file doesn't
+	// hold back the rest of the registry.
 	// TODO: If we wanted to be more creative we could first check
 	// to see if the file had actually changed since the last time
 	// it was pulled
-	for _, chain := range h.chains {
-		if err := h.getChain(chain); err != nil {
-			return err
+	results := h.fetchChains(ctx, next.chains)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	chainErrors := next.chainErrors
+	for idx, chain := range next.chains {
+		result := results[idx]
+		if result.chainErr != nil {
+			h.log.Error("pulling chain", "chain", chain, "err", result.chainErr, "stale", current.retainChain(next, chain))
+			chainErrors[chain] = result.chainErr
+		} else if result.chain != nil {
+			next.chainList[chain] = *result.chain
+			next.chainById[result.chain.ChainID] = chain
+			next.provenanceOf(chain).Chain = result.provenance.Chain
 		}
-		if err := h.getAssetList(chain); err != nil {
-			return err
+		if result.assetListErr != nil {
+			h.log.Error("pulling asset list", "chain", chain, "err", result.assetListErr, "stale", current.retainAssetList(next, chain))
+			err := result.assetListErr
+			if prev, ok := chainErrors[chain]; ok {
+				err = fmt.Errorf("%v; %w", prev, err)
+			}
+			chainErrors[chain] = err
+		} else if result.assetList != nil {
+			next.assetList[chain] = *result.assetList
+			next.provenanceOf(chain).AssetList = result.provenance.AssetList
 		}
 	}
-
-	// Index assets by display
-	assets := make([]string, 0)
-	for _, assetList := range h.assetList {
-		name := h.chainById[assetList.ChainID]
-		for _, asset := range assetList.Assets {
-			assets = append(assets, asset.Display)
-			h.chainByAsset[asset.Display] = name
+	h.status.setChainErrors(chainErrors)
+	h.metrics.setFailingChains(len(chainErrors))
+
+	sort.Strings(next.chains)
+	next.indexAssets()
+	next.indexOrigins()
+	// responses are rendered up front rather than on every request. If this
+	// fails they are rendered per request instead.
+	if err := next.render(); err != nil {
+		h.log.Error("rendering registry", "err", err)
+		next.rendered = make(map[string]*payload)
+	}
+
+	// update timestamp and swap in the new snapshot. A snapshot with the same
+	// content as the current one keeps its modification time.
+	next.commit = commit
+	next.lastUpdated = time.Now()
+	next.modified = next.lastUpdated
+	if version, err := next.fingerprint(); err != nil {
+		h.log.Error("fingerprinting registry", "err", err)
+		next.version = commit + "@" + strconv.FormatInt(next.modified.UnixNano(), 10)
+	} else {
+		next.version = version
+		if version == current.version && !current.modified.IsZero() {
+			next.modified = current.modified
 		}
 	}
-
-	// update timestamp
-	h.lastUpdated = time.Now()
-	h.log.Printf("successfully updated registry (%d chains)", len(h.chains))
+	h.current.Store(next)
+	h.metrics.setRegistry(len(next.chains), len(next.assets), next.lastUpdated)
+	h.status.updated(commit, next.lastUpdated, len(next.chains), len(next.assets))
+	h.log.Info("updated registry", "commit", commit, "chains", len(next.chains), "assets", len(next.assets),
+		"failed", len(chainErrors), "duration", time.Since(start))
 
 	return nil
 }
 
-func (h *Handler) getChains() error {
-	query := fmt.Sprintf("https://api.github.com/repos/%s/contents", h.registryUrl)
-	resp, err := http.Get(query)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code from query %s: %d", query, resp.StatusCode)
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
-	var repo []map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &repo); err != nil {
-		return fmt.Errorf("unmarshalling repo: %w", err)
-	}
+// chainResult holds the files fetched for a single chain. A nil file without
+// an error means it doesn't exist in the registry.
+type chainResult struct {
+	chain        *types.Chain
+	chainErr     error
+	assetList    *types.AssetList
+	assetListErr error
+	// which layer each field came from if the source merges registries
+	provenance types.ChainProvenance
+}
 
-	chains := make([]string, 0)
-	for _, entry := range repo {
-		// only accept directories
-		entryType := entry["type"].(string)
-		if entryType != "dir" {
-			continue
-		}
+// provenancer is implemented by sources that merge several registries and
+// can report which one each field of a file came from
+type provenancer interface {
+	Provenance(path string) map[string]string
+}
 
-		name := entry["name"].(string)
-		if strings.Contains(name, "testnets") {
-			continue
+// fetchChains fetches the chain.json and assetlist.json of every chain using
+// at most h.concurrency workers. Results are returned in the same order as
+// chains.
+func (h *Handler) fetchChains(ctx context.Context, chains []string) []chainResult {
+	results := make([]chainResult, len(chains))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < h.concurrency && worker < len(chains); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				result := &results[idx]
+				result.chain, result.chainErr = h.getChain(ctx, chains[idx])
+				result.assetList, result.assetListErr = h.getAssetList(ctx, chains[idx])
+				if source, ok := h.source.(provenancer); ok {
+					result.provenance.Chain = source.Provenance(chains[idx] + "/chain.json")
+					result.provenance.AssetList = source.Provenance(chains[idx] + "/assetlist.json")
+				}
+			}
+		}()
+	}
+	for idx := range chains {
+		select {
+		case jobs <- idx:
+		case <-ctx.Done():
 		}
-		if strings.Contains(name, ".") {
-			continue
+		if ctx.Err() != nil {
+			break
 		}
-
-		chains = append(chains, name)
-
-		fmt.Printf("%s, ", name)
 	}
-	fmt.Printf("\n")
-	h.chains = chains
-	fmt.Print(len(h.chains))
-	return nil
+	close(jobs)
+	wg.Wait()
+	return results
 }
 
-func (h *Handler) getChain(name string) error {
-	query := fmt.Sprintf("https://raw.githubusercontent.com/%s/master/%s/chain.json", h.registryUrl, name)
-	resp, err := http.Get(query)
-	if err != nil {
-		return err
-	}
-
+// getChain fetches and parses a chain's chain.json. It returns nil if the
+// chain doesn't have one.
+func (h *Handler) getChain(ctx context.Context, name string) (*types.Chain, error) {
+	bodyBytes, err := h.source.ReadFile(ctx, name+"/chain.json")
 	// If the chain.json file doesn't exist we simply ignore it
-	if resp.StatusCode == http.StatusNotFound {
-		return nil
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code from query %s: %d", query, resp.StatusCode)
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var chain types.Chain
 	err = json.Unmarshal(bodyBytes, &chain)
 	if err != nil {
-		return err
+		h.metrics.parseError(name)
+		return nil, err
 	}
-
-	h.chainList[name] = chain
-	h.chainById[chain.ChainID] = name
-	return nil
+	return &chain, nil
 }
 
-func (h *Handler) getAssetList(name string) error {
-	query := fmt.Sprintf("https://raw.githubusercontent.com/%s/master/%s/assetlist.json", h.registryUrl, name)
-	resp, err := http.Get(query)
-	if err != nil {
-		return err
-	}
-
-	// If the chain.json file doesn't exist we simply ignore it
-	if resp.StatusCode == http.StatusNotFound {
-		return nil
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code for query %s: %d", query, resp.StatusCode)
+// getAssetList fetches and parses a chain's assetlist.json. It returns nil if
+// the chain doesn't have one.
+func (h *Handler) getAssetList(ctx context.Context, name string) (*types.AssetList, error) {
+	bodyBytes, err := h.source.ReadFile(ctx, name+"/assetlist.json")
+	// If the assetlist.json file doesn't exist we simply ignore it
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
 	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var assetList types.AssetList
 	err = json.Unmarshal(bodyBytes, &assetList)
 	if err != nil {
-		return err
+		h.metrics.parseError(name)
+		return nil, err
 	}
-
-	h.assetList[name] = assetList
-	return nil
-}
-
-// recentCommits returns true if there has been a commit more recent than the time the handler
-// last updated
-func (h Handler) recentCommits() (bool, error) {
-	lastUpdated := h.lastUpdated.Format(time.RFC3339)
-	query := fmt.Sprintf("https://api.github.com/repos/%s/commits?since=%s", h.registryUrl, lastUpdated)
-	resp, err := http.Get(query)
-	if err != nil {
-		return false, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("unexpected status code for query %s: %d", query, resp.StatusCode)
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-
-	var body []interface{}
-	err = json.Unmarshal(bodyBytes, &body)
-	if err != nil {
-		return false, err
-	}
-
-	return len(body) > 0, nil
+	return &assetList, nil
 }
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// registry is a snapshot of the chain registry. Once it has been swapped in
+// by Pull it is never modified so handlers can read it without locking.
+type registry struct {
+	commit       string // sha of the registry commit the snapshot was built from
+	lastUpdated  time.Time
+	modified     time.Time // when the content of the snapshot last changed. Zero until the registry is loaded
+	version      string    // hash of the content of the snapshot, see fingerprint
+	chains       []string
+	assets       []string
+	chainByAsset map[string]string // asset name -> chain name
+	chainById    map[string]string // chain id -> chain name
+	chainList    map[string]types.Chain
+	assetList    map[string]types.AssetList
+	// indexes for tracing assets across chains
+	assetByRef      map[assetRef]types.AssetElement
+	nativeByDenom   map[string][]assetRef   // base denom -> assets not transferred from another chain
+	originByAsset   map[assetRef]assetRef   // asset -> asset it originated from
+	representations map[assetRef][]assetRef // origin -> all other representations
+	// which layer each field of a chain came from if registries are merged
+	provenance map[string]*types.ChainProvenance
+	// chains that failed to be pulled into this snapshot
+	chainErrors map[string]error
+	// responses rendered once per snapshot, keyed by renderKey
+	rendered map[string]*payload
+}
+
+func newRegistry() *registry {
+	return &registry{
+		lastUpdated:     time.Unix(0, 0),
+		chains:          make([]string, 0),
+		assets:          make([]string, 0),
+		chainByAsset:    make(map[string]string),
+		chainById:       make(map[string]string),
+		chainList:       make(map[string]types.Chain),
+		assetList:       make(map[string]types.AssetList),
+		assetByRef:      make(map[assetRef]types.AssetElement),
+		nativeByDenom:   make(map[string][]assetRef),
+		originByAsset:   make(map[assetRef]assetRef),
+		representations: make(map[assetRef][]assetRef),
+		provenance:      make(map[string]*types.ChainProvenance),
+		chainErrors:     make(map[string]error),
+		rendered:        make(map[string]*payload),
+	}
+}
+
+func (r *registry) findChain(name string) (bool, types.Chain) {
+	chain, ok := r.chainList[name]
+	if ok {
+		return true, chain
+	}
+
+	name, ok = r.chainById[name]
+	if !ok {
+		return false, types.Chain{}
+	}
+
+	return true, r.chainList[name]
+}
+
+// findAsset returns the asset with the display name from the chain it is
+// indexed under
+func (r *registry) findAsset(display string) (types.AssetElement, bool) {
+	chainName, ok := r.chainByAsset[display]
+	if !ok {
+		return types.AssetElement{}, false
+	}
+	for _, asset := range r.assetList[chainName].Assets {
+		if asset.Display == display {
+			return asset, true
+		}
+	}
+	return types.AssetElement{}, false
+}
+
+// indexAssets indexes assets by their display name. If several chains have an
+// asset with the same display name, the chain it is native to is served,
+// i.e. cosmoshub rather than the chains holding IBC vouchers of its atom.
+// Otherwise the last chain is served.
+func (r *registry) indexAssets() {
+	native := make(map[string]bool) // display -> whether the indexed asset is native
+	for _, chain := range r.chains {
+		// findAsset serves the first asset of a chain with the display name
+		seen := make(map[string]bool)
+		for _, asset := range r.assetList[chain].Assets {
+			if seen[asset.Display] {
+				continue
+			}
+			seen[asset.Display] = true
+			if _, ok := r.chainByAsset[asset.Display]; !ok {
+				r.assets = append(r.assets, asset.Display)
+			} else if native[asset.Display] && !isNative(asset) {
+				continue
+			}
+			r.chainByAsset[asset.Display] = chain
+			native[asset.Display] = isNative(asset)
+		}
+	}
+	sort.Strings(r.assets)
+}
+
+// retainChain copies a chain from r into next, returning whether there was a
+// previous version to keep
+func (r *registry) retainChain(next *registry, name string) bool {
+	chain, ok := r.chainList[name]
+	if !ok {
+		return false
+	}
+	next.chainList[name] = chain
+	next.chainById[chain.ChainID] = name
+	if provenance, ok := r.provenance[name]; ok {
+		next.provenanceOf(name).Chain = provenance.Chain
+	}
+	return true
+}
+
+// retainAssetList copies a chain's asset list from r into next, returning
+// whether there was a previous version to keep
+func (r *registry) retainAssetList(next *registry, name string) bool {
+	assetList, ok := r.assetList[name]
+	if !ok {
+		return false
+	}
+	next.assetList[name] = assetList
+	if provenance, ok := r.provenance[name]; ok {
+		next.provenanceOf(name).AssetList = provenance.AssetList
+	}
+	return true
+}
+
+// provenanceOf returns the provenance of a chain, creating it if it doesn't
+// exist. It must only be used while building a snapshot.
+func (r *registry) provenanceOf(name string) *types.ChainProvenance {
+	provenance, ok := r.provenance[name]
+	if !ok {
+		provenance = &types.ChainProvenance{}
+		r.provenance[name] = provenance
+	}
+	return provenance
+}
+
+// renderKey identifies a pre-rendered response of a snapshot
+func renderKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// render pre-renders the responses that don't depend on the query: the
+// lists of chains and assets and every chain, asset list and asset. It must
+// be called before the snapshot is swapped in.
+func (r *registry) render() error {
+	add := func(key string, v interface{}) error {
+		p, err := newPayload(v)
+		if err != nil {
+			return err
+		}
+		r.rendered[key] = p
+		return nil
+	}
+	if err := add(renderKey("chains", ""), r.chains); err != nil {
+		return err
+	}
+	if err := add(renderKey("assets", ""), r.assets); err != nil {
+		return err
+	}
+	for name, chain := range r.chainList {
+		if err := add(renderKey("chain", name), chain); err != nil {
+			return err
+		}
+	}
+	for name, assetList := range r.assetList {
+		if err := add(renderKey("assetlist", name), assetList); err != nil {
+			return err
+		}
+		// the first asset with a display name is served, as by Asset
+		for _, asset := range assetList.Assets {
+			if _, ok := r.rendered[renderKey("asset", asset.Display)]; ok || r.chainByAsset[asset.Display] != name {
+				continue
+			}
+			if err := add(renderKey("asset", asset.Display), asset); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
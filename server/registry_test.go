@@ -0,0 +1,59 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+func TestIndexAssets(t *testing.T) {
+	native := func(display string) types.AssetElement {
+		return types.AssetElement{Base: "u" + display, Display: display}
+	}
+	voucher := func(display string) types.AssetElement {
+		return types.AssetElement{Base: "ibc/" + display, Display: display, Traces: []types.Trace{{Type: types.TraceTypeIbc}}}
+	}
+	testCases := []struct {
+		name     string
+		chains   map[string][]types.AssetElement
+		expected string
+	}{
+		{"native chain first", map[string][]types.AssetElement{
+			"cosmoshub": {native("atom")},
+			"osmosis":   {voucher("atom")},
+		}, "cosmoshub"},
+		{"native chain in the middle", map[string][]types.AssetElement{
+			"akash":     {voucher("atom")},
+			"cosmoshub": {native("atom")},
+			"osmosis":   {voucher("atom")},
+		}, "cosmoshub"},
+		{"no native chain", map[string][]types.AssetElement{
+			"akash":   {voucher("atom")},
+			"osmosis": {voucher("atom")},
+		}, "osmosis"},
+		{"several native chains", map[string][]types.AssetElement{
+			"akash":   {native("atom")},
+			"osmosis": {native("atom")},
+		}, "osmosis"},
+		{"first asset of a chain", map[string][]types.AssetElement{
+			"cosmoshub": {native("atom")},
+			"osmosis":   {voucher("atom"), native("atom")},
+		}, "cosmoshub"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := newRegistry()
+			for chain, assets := range tc.chains {
+				reg.assetList[chain] = types.AssetList{Assets: assets}
+			}
+			reg.chains = sortedKeys(tc.chains)
+			reg.indexAssets()
+			if len(reg.assets) != 1 || reg.assets[0] != "atom" {
+				t.Fatalf("expected assets [atom], got %v", reg.assets)
+			}
+			if chain := reg.chainByAsset["atom"]; chain != tc.expected {
+				t.Fatalf("expected atom to be served from %s, got %s", tc.expected, chain)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package server
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"sync"
 
 	"github.com/gorilla/mux"
 	cron "github.com/robfig/cron/v3"
@@ -12,62 +15,166 @@ import (
 // Serve starts a server listening on "listenAddr". In parrallel, a cron-like job
 // is also started, pulling the latest registry changes from the provided registry-url
 // This function is blocking and can be stopped by cancelling the provided context.
-func Serve(ctx context.Context, registryUrl, listenAddr, updateFreq string) error {
-	l := log.Default()
-	// Set up the handler and pull in all data
-	handler := NewHandler(registryUrl, l)
-	if err := handler.Pull(ctx); err != nil {
+// On cancellation, running pulls are aborted and in-flight requests are given
+// until the shutdown timeout to complete.
+func Serve(ctx context.Context, registryUrl, listenAddr, updateFreq string, opts ...Option) error {
+	o := newOptions(opts)
+	l := o.logger
+	if err := o.auth.validate(); err != nil {
 		return err
 	}
+	// Set up the handler. The registry is pulled once the server is up so
+	// that /readyz can report when it has been loaded
+	handler := NewHandler(registryUrl, l, opts...)
+	if handler.overrides != nil && !handler.auth.hasScope(ScopeAdmin) {
+		return errors.New("an admin API key is required to enable overrides")
+	}
 
-	// create a router to handle inbound requests
-	router := mux.NewRouter()
-	router.HandleFunc("/", Ok).Methods("GET")
-	// use some form of versioning to allow for future changes
-	v1Router := router.PathPrefix("/v1").Subrouter()
-	v1Router.HandleFunc("/chains", handler.Chains).Methods("GET")
-	v1Router.HandleFunc("/chain/{chain}", handler.Chain).Methods("GET")
-	v1Router.HandleFunc("/chain/{chain}/endpoints/{type}", handler.Endpoints).Methods("GET")
-	v1Router.HandleFunc("/chain/{chain}/assets", handler.ChainAsset).Methods("GET")
-	v1Router.HandleFunc("/assets", handler.Assets).Methods("GET")
-	v1Router.HandleFunc("/asset/{asset}", handler.Asset).Methods("GET")
-	s := http.Server{Addr: listenAddr, Handler: router}
+	router := newRouter(handler)
+	// middleware that applies to every request, listed from the innermost.
+	// Metrics are outermost so that every response is counted.
+	var root http.Handler = compress(router)
+	root = handler.auth.middleware(root)
+	root = newCORS(o.cors).middleware(root)
+	root = recoverPanics(l, root)
+	root = accessLog(l, root)
+	root = handler.metrics.middleware(router, root)
+	s := http.Server{
+		Addr:     listenAddr,
+		Handler:  root,
+		ErrorLog: slog.NewLogLogger(l.Handler(), slog.LevelError),
+	}
 
 	errs := make(chan error, 1)
 	go func() {
 		// If there is an error on startup catch it and pass it through
 		// the channel
-		errs <- s.ListenAndServe()
+		if err := s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+		close(errs)
 	}()
 
-	l.Printf("server up on %s", s.Addr)
+	l.Info("server up", "addr", s.Addr)
 
-	crawler := cron.New(cron.WithLogger(cron.PrintfLogger(l)))
-	crawler.AddFunc(updateFreq, func() {
+	// pulls are cancelled as soon as the server begins shutting down
+	pullCtx, cancelPulls := context.WithCancel(ctx)
+	defer cancelPulls()
+
+	var pulls sync.WaitGroup
+	pulls.Add(1)
+	go func() {
+		defer pulls.Done()
+		// pull in all data. Until this succeeds the server reports that it
+		// is not ready
+		if err := handler.Pull(pullCtx); err != nil {
+			l.Error("initial pull failed", "err", err)
+		}
+	}()
+
+	crawler := cron.New(cron.WithLogger(cronLogger{l}))
+	_, err := crawler.AddFunc(updateFreq, func() {
 		// update the servers local records
-		if err := handler.Pull(ctx); err != nil {
-			l.Print(err)
+		if err := handler.Pull(pullCtx); err != nil {
+			l.Error("pull failed", "err", err)
 		}
 	})
+	if err != nil {
+		cancelPulls()
+		_ = s.Close()
+		return fmt.Errorf("invalid update frequency %q: %w", updateFreq, err)
+	}
 	crawler.Start()
-	defer crawler.Stop()
 
-	l.Printf("cron scheduler running with update frequency: %s", updateFreq)
+	// pull whenever overrides change
+	pulls.Add(1)
+	go func() {
+		defer pulls.Done()
+		for {
+			select {
+			case <-handler.pullRequests:
+				if err := handler.Pull(pullCtx); err != nil {
+					l.Error("pull failed", "err", err)
+				}
+			case <-pullCtx.Done():
+				return
+			}
+		}
+	}()
+
+	l.Info("cron scheduler running", "update_frequency", updateFreq)
 
-	select {
 	// Use contexts to manage the servers lifecycle
+	select {
 	case <-ctx.Done():
-		// This will stop the other go routine if it hasn't already
-		// stopped yet
-		l.Print("shutting down server")
-		if err := s.Close(); err != nil {
-			return err
-		}
-		err := <-errs
-		return err
-	case err := <-errs:
+		l.Info("shutting down server", "timeout", o.shutdownTimeout)
+	case err = <-errs:
+	}
+
+	// abort any running pulls and genesis downloads and wait for the pulls to
+	// return
+	cancelPulls()
+	if handler.genesis != nil {
+		handler.genesis.stop()
+	}
+	<-crawler.Stop().Done()
+	pulls.Wait()
+
+	// stop accepting new connections and wait for in-flight requests to
+	// complete, dropping them if they exceed the timeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), o.shutdownTimeout)
+	defer cancel()
+	if shutdownErr := s.Shutdown(shutdownCtx); shutdownErr != nil {
+		l.Warn("in-flight requests dropped", "err", shutdownErr)
+		_ = s.Close()
+	}
+	if err != nil {
 		return err
 	}
+	return <-errs
+}
+
+// newRouter routes the endpoints of the server to the handler. Endpoints that
+// read the registry answer HEAD requests as well as GET.
+func newRouter(handler *Handler) *mux.Router {
+	router := mux.NewRouter()
+	router.NotFoundHandler = http.HandlerFunc(routeNotFound)
+	router.MethodNotAllowedHandler = http.HandlerFunc(methodNotAllowed)
+	probes := router.NewRoute().Subrouter()
+	probes.Use(noStore)
+	probes.HandleFunc("/", Ok).Methods("GET")
+	probes.HandleFunc("/healthz", Healthz).Methods("GET")
+	probes.HandleFunc("/readyz", handler.Readyz).Methods("GET")
+	probes.Handle("/metrics", handler.metrics).Methods("GET")
+	// use some form of versioning to allow for future changes
+	v1Router := router.PathPrefix("/v1").Subrouter()
+	adminRouter := v1Router.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(requireScope(ScopeAdmin), noStore)
+	adminRouter.HandleFunc("/chain/{chain}", handler.AdminChain).Methods("GET", "PUT", "PATCH", "DELETE")
+	adminRouter.HandleFunc("/chain/{chain}/assets", handler.AdminAssets).Methods("GET", "PUT", "PATCH", "DELETE")
+	v1Router.HandleFunc("/status", handler.Status).Methods("GET", "HEAD")
+	v1Router.HandleFunc("/chain/{chain}/genesis", handler.Genesis).Methods("GET", "HEAD")
+	v1Router.HandleFunc("/chain/{chain}/genesis/checksum", handler.GenesisChecksum).Methods("GET", "HEAD")
+	// responses derived from the registry are cached until it is updated
+	registryRouter := v1Router.NewRoute().Subrouter()
+	registryRouter.Use(handler.cacheSnapshot)
+	registryRouter.HandleFunc("/chains", handler.Chains).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/chain/{chain}", handler.Chain).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/chain/{chain}/endpoints/{type}", handler.Endpoints).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/chain/{chain}/assets", handler.ChainAsset).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/chain/{chain}/bootstrap", handler.Bootstrap).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/chain/{chain}/binaries", handler.Binaries).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/chain/{chain}/fees", handler.Fees).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/chain/{chain}/address/{address}", handler.ChainAddress).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/chain/{chain}/provenance", handler.ChainProvenance).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/assets", handler.Assets).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/asset/{asset}", handler.Asset).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/asset/{asset}/origin", handler.AssetOrigin).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/asset/{asset}/representations", handler.AssetRepresentations).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/convert", handler.Convert).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/address/convert", handler.ConvertAddress).Methods("GET", "HEAD")
+	registryRouter.HandleFunc("/address/{address}", handler.Address).Methods("GET", "HEAD")
+	return router
 }
 
 func Ok(res http.ResponseWriter, req *http.Request) {
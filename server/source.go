@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Source provides the files of a chain registry. Paths are slash separated
+// and relative to the root of the registry, i.e. "cosmoshub/chain.json".
+// Implementations must be safe for concurrent use by ReadFile.
+type Source interface {
+	// Revision brings the source up to date and returns an identifier for
+	// the version of the registry that it now serves, such as a commit sha.
+	// An empty revision means the version is unknown and the registry is
+	// always pulled in full.
+	Revision(ctx context.Context) (string, error)
+	// Chains lists the names of the chain directories in the registry
+	Chains(ctx context.Context) ([]string, error)
+	// ReadFile returns the contents of a file. If the file does not exist,
+	// the error wraps fs.ErrNotExist.
+	ReadFile(ctx context.Context, path string) ([]byte, error)
+	// String describes where the registry is read from as source:location,
+	// i.e. "git:https://github.com/cosmos/chain-registry#master". It is
+	// reported by the status endpoint so it must not contain credentials.
+	String() string
+}
+
+// redactURL removes credentials and the query, which may hold a token, from
+// a url. Values that aren't urls with a host, such as local paths, are
+// returned unchanged.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	u.User, u.RawQuery, u.Fragment = nil, "", ""
+	return u.String()
+}
+
+// isChainDir reports whether a top level directory of the registry holds a
+// mainnet chain
+func isChainDir(name string) bool {
+	return !strings.Contains(name, "testnets") && !strings.Contains(name, ".") && !strings.HasPrefix(name, "_")
+}
+
+// metricsUser is implemented by sources that report metrics
+type metricsUser interface {
+	useMetrics(m *metrics)
+}
+
+// githubSource reads the registry from a GitHub repository through the REST
+// API and raw.githubusercontent.com
+type githubSource struct {
+	repo    string // owner/name
+	timeout time.Duration
+	metrics *metrics
+}
+
+// NewGitHubSource creates a source that reads the registry from the GitHub
+// repository "owner/name" through the GitHub API. Each request times out after
+// timeout.
+func NewGitHubSource(repo string, timeout time.Duration) Source {
+	return &githubSource{repo: repo, timeout: timeout}
+}
+
+func (s *githubSource) String() string {
+	return "github:" + s.repo
+}
+
+func (s *githubSource) useMetrics(m *metrics) {
+	s.metrics = m
+}
+
+func (s *githubSource) Revision(ctx context.Context) (string, error) {
+	query := fmt.Sprintf("https://api.github.com/repos/%s/commits?per_page=1", s.repo)
+	status, bodyBytes, err := s.fetch(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code for query %s: %d", query, status)
+	}
+
+	var body []struct {
+		SHA string `json:"sha"`
+	}
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		return "", err
+	}
+	if len(body) == 0 {
+		return "", fmt.Errorf("no commits in %s", s.repo)
+	}
+	return body[0].SHA, nil
+}
+
+func (s *githubSource) Chains(ctx context.Context) ([]string, error) {
+	query := fmt.Sprintf("https://api.github.com/repos/%s/contents", s.repo)
+	status, bodyBytes, err := s.fetch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from query %s: %d", query, status)
+	}
+
+	var repo []map[string]interface{}
+	if err := json.Unmarshal(bodyBytes, &repo); err != nil {
+		return nil, fmt.Errorf("unmarshalling repo: %w", err)
+	}
+
+	chains := make([]string, 0)
+	for _, entry := range repo {
+		// only accept directories
+		entryType, _ := entry["type"].(string)
+		if entryType != "dir" {
+			continue
+		}
+		name, _ := entry["name"].(string)
+		if isChainDir(name) {
+			chains = append(chains, name)
+		}
+	}
+	return chains, nil
+}
+
+func (s *githubSource) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	query := fmt.Sprintf("https://raw.githubusercontent.com/%s/master/%s", s.repo, path)
+	status, bodyBytes, err := s.fetch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", query, fs.ErrNotExist)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code for query %s: %d", query, status)
+	}
+	return bodyBytes, nil
+}
+
+// fetch performs a GET request bound to ctx and limited to the source's
+// request timeout, returning the status code and body. It keeps track of the
+// remaining GitHub API rate limit.
+func (s *githubSource) fetch(ctx context.Context, query string) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, query, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	if s.metrics != nil {
+		s.metrics.observeRateLimit(resp)
+	}
+
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, bodyBytes, nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// Healthz reports that the server is alive
+func Healthz(res http.ResponseWriter, req *http.Request) {
+	respondWithText(res, []byte("ok\n"))
+}
+
+// Readyz reports whether the server is ready to serve requests. This is
+// only the case once the registry has been loaded with at least one chain.
+func (h Handler) Readyz(res http.ResponseWriter, req *http.Request) {
+	if !h.status.isReady() {
+		respondWithError(res, http.StatusServiceUnavailable, types.ErrCodeNotReady, "no chains loaded from the registry", nil)
+		return
+	}
+	respondWithText(res, []byte("ok\n"))
+}
+
+// Status returns detailed information on the state of the loaded registry
+func (h Handler) Status(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Cache-Control", "no-cache")
+	respondWithJSON(res, h.status.report())
+}
+
+// status tracks the outcome of pulls from the registry. It is safe for
+// concurrent use.
+type status struct {
+	mtx         sync.RWMutex
+	source      string
+	ready       bool
+	commit      string
+	lastUpdated time.Time
+	lastError   error
+	lastErrorAt time.Time
+	chainErrors map[string]error
+	chains      int
+	assets      int
+}
+
+func newStatus(source string) *status {
+	return &status{
+		source:      source,
+		lastUpdated: time.Unix(0, 0),
+		chainErrors: make(map[string]error),
+	}
+}
+
+func (s *status) isReady() bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.ready
+}
+
+// pulled records the outcome of a pull. A successful pull that loaded no
+// chains, i.e. from an empty or misconfigured source, doesn't make the server
+// ready.
+func (s *status) pulled(err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err != nil {
+		s.lastError, s.lastErrorAt = err, time.Now()
+		return
+	}
+	s.lastError = nil
+	s.ready = s.chains > 0
+}
+
+// updated records a successful update of the registry
+func (s *status) updated(commit string, lastUpdated time.Time, chains, assets int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if commit != "" {
+		s.commit = commit
+	}
+	s.lastUpdated, s.chains, s.assets = lastUpdated, chains, assets
+}
+
+// setChainErrors records the chains that failed to be pulled in the last
+// update
+func (s *status) setChainErrors(chainErrors map[string]error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.chainErrors = chainErrors
+}
+
+func (s *status) report() types.ServerStatus {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	report := types.ServerStatus{
+		Source:      s.source,
+		Commit:      s.commit,
+		Ready:       s.ready,
+		LastUpdated: s.lastUpdated,
+		ChainErrors: make(map[string]string, len(s.chainErrors)),
+		Chains:      s.chains,
+		Assets:      s.assets,
+	}
+	if s.lastError != nil {
+		lastErrorAt := s.lastErrorAt
+		report.LastError = s.lastError.Error()
+		report.LastErrorAt = &lastErrorAt
+	}
+	for chain, err := range s.chainErrors {
+		report.ChainErrors[chain] = err.Error()
+	}
+	return report
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cmwaters/skychart/types"
+)
+
+// maxHops bounds how far an asset is traced back in case of cycles in the
+// registry
+const maxHops = 16
+
+// assetRef uniquely identifies an asset by the chain it is on and its base
+// denom on that chain
+type assetRef struct {
+	chain string
+	denom string
+}
+
+// AssetOrigin walks the traces of an asset back to the chain and asset that it
+// originated from. An optional "chain" query parameter selects the chain of
+// the asset if multiple chains share the same display name.
+func (h Handler) AssetOrigin(res http.ResponseWriter, req *http.Request) {
+	reg := h.snapshot()
+	ref, ok := reg.assetRef(res, req)
+	if !ok {
+		return
+	}
+
+	path := reg.trace(ref)
+	origin := path[len(path)-1]
+	asset, _ := reg.lookupAsset(assetRef{chain: origin.ChainName, denom: origin.Denom})
+	respondWithJSON(res, types.AssetOrigin{
+		ChainName: origin.ChainName,
+		Asset:     asset,
+		Path:      path,
+	})
+}
+
+// AssetRepresentations lists every chain on which the same underlying asset
+// can be found together with the asset's local denom on that chain.
+func (h Handler) AssetRepresentations(res http.ResponseWriter, req *http.Request) {
+	reg := h.snapshot()
+	ref, ok := reg.assetRef(res, req)
+	if !ok {
+		return
+	}
+
+	origin, ok := reg.originByAsset[ref]
+	if !ok {
+		origin = ref
+	}
+	refs := append([]assetRef{origin}, reg.representations[origin]...)
+	resp := make([]types.AssetRepresentation, 0, len(refs))
+	for _, ref := range refs {
+		asset, ok := reg.lookupAsset(ref)
+		if !ok {
+			continue
+		}
+		resp = append(resp, types.AssetRepresentation{
+			ChainName: ref.chain,
+			ChainID:   reg.assetList[ref.chain].ChainID,
+			Denom:     asset.Base,
+			Display:   asset.Display,
+			Symbol:    asset.Symbol,
+			Origin:    ref == origin,
+		})
+	}
+	respondWithJSON(res, resp)
+}
+
+// assetRef resolves the asset of a request, writing the appropriate error
+// response if it doesn't exist
+func (r *registry) assetRef(res http.ResponseWriter, req *http.Request) (assetRef, bool) {
+	vars := mux.Vars(req)
+	assetName, ok := vars["asset"]
+	if !ok {
+		badRequest(res, "missing asset")
+		return assetRef{}, false
+	}
+
+	chainName := req.URL.Query().Get("chain")
+	if chainName == "" {
+		chainName, ok = r.chainByAsset[assetName]
+		if !ok {
+			resourceNotFound(res, "asset %s not found", assetName)
+			return assetRef{}, false
+		}
+	} else if name, ok := r.chainById[chainName]; ok {
+		chainName = name
+	}
+
+	for _, asset := range r.assetList[chainName].Assets {
+		if asset.Display == assetName {
+			return assetRef{chain: chainName, denom: asset.Base}, true
+		}
+	}
+	resourceNotFound(res, "asset %s not found on %s", assetName, chainName)
+	return assetRef{}, false
+}
+
+func (r *registry) lookupAsset(ref assetRef) (types.AssetElement, bool) {
+	asset, ok := r.assetByRef[ref]
+	return asset, ok
+}
+
+// trace follows an asset back to its origin returning each hop along the way.
+// The last hop is the origin.
+func (r *registry) trace(ref assetRef) []types.AssetHop {
+	path := []types.AssetHop{{ChainName: ref.chain, Denom: ref.denom}}
+	visited := map[assetRef]bool{ref: true}
+	for len(path) < maxHops {
+		asset, ok := r.lookupAsset(ref)
+		if !ok {
+			break
+		}
+		prev, kind, ok := r.previousHop(asset)
+		if !ok || visited[prev] {
+			break
+		}
+		path[len(path)-1].Type = kind
+		path = append(path, types.AssetHop{ChainName: prev.chain, Denom: prev.denom})
+		visited[prev] = true
+		ref = prev
+	}
+	return path
+}
+
+// previousHop returns where an asset came from and how. Only the transfer of
+// the same asset between chains, over ibc or a bridge, is followed; other
+// traces such as liquid staking or synthetics create a new asset, which is its
+// own origin. The most recent trace is used. Assets that only carry the
+// deprecated ibc field are resolved by looking for a unique native asset with
+// the source denom.
+func (r *registry) previousHop(asset types.AssetElement) (assetRef, string, bool) {
+	if len(asset.Traces) > 0 {
+		trace := asset.Traces[len(asset.Traces)-1]
+		if !isTransfer(trace.Type) {
+			return assetRef{}, "", false
+		}
+		return assetRef{chain: trace.Counterparty.ChainName, denom: trace.Counterparty.BaseDenom}, string(trace.Type), true
+	}
+	if asset.Ibc == nil {
+		return assetRef{}, "", false
+	}
+
+	sources := r.nativeByDenom[asset.Ibc.SourceDenom]
+	if len(sources) != 1 {
+		// missing or ambiguous
+		return assetRef{}, "", false
+	}
+	return sources[0], string(types.TraceTypeIbc), true
+}
+
+// isTransfer reports whether a trace moves an asset between chains rather
+// than deriving a new asset from it
+func isTransfer(traceType types.TraceType) bool {
+	switch traceType {
+	case types.TraceTypeIbc, types.TraceTypeIbcCw20, types.TraceTypeBridge:
+		return true
+	default:
+		return false
+	}
+}
+
+// isNative reports whether an asset wasn't transferred from another chain
+func isNative(asset types.AssetElement) bool {
+	if asset.Ibc != nil {
+		return false
+	}
+	return len(asset.Traces) == 0 || !isTransfer(asset.Traces[len(asset.Traces)-1].Type)
+}
+
+// indexOrigins traces every asset back to its origin so that all
+// representations of the same asset can be found
+func (r *registry) indexOrigins() {
+	assetByRef := make(map[assetRef]types.AssetElement)
+	nativeByDenom := make(map[string][]assetRef)
+	for chain, assetList := range r.assetList {
+		for _, asset := range assetList.Assets {
+			ref := assetRef{chain: chain, denom: asset.Base}
+			if _, ok := assetByRef[ref]; ok {
+				continue
+			}
+			assetByRef[ref] = asset
+			if isNative(asset) {
+				nativeByDenom[asset.Base] = append(nativeByDenom[asset.Base], ref)
+			}
+		}
+	}
+	r.assetByRef = assetByRef
+	r.nativeByDenom = nativeByDenom
+
+	originByAsset := make(map[assetRef]assetRef)
+	representations := make(map[assetRef][]assetRef)
+	for chain, assetList := range r.assetList {
+		for _, asset := range assetList.Assets {
+			ref := assetRef{chain: chain, denom: asset.Base}
+			path := r.trace(ref)
+			origin := assetRef{chain: path[len(path)-1].ChainName, denom: path[len(path)-1].Denom}
+			originByAsset[ref] = origin
+			if ref != origin {
+				representations[origin] = append(representations[origin], ref)
+			}
+		}
+	}
+	for _, refs := range representations {
+		sort.Slice(refs, func(i, j int) bool {
+			if refs[i].chain != refs[j].chain {
+				return refs[i].chain < refs[j].chain
+			}
+			return refs[i].denom < refs[j].denom
+		})
+	}
+	r.originByAsset = originByAsset
+	r.representations = representations
+}
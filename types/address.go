@@ -0,0 +1,9 @@
+package types
+
+// AddressInfo describes a bech32 address and the chains it may belong to
+type AddressInfo struct {
+	Address string   `json:"address"`
+	Prefix  string   `json:"prefix"` // The human readable part of the address
+	Bytes   string   `json:"bytes"`  // Hex encoded address bytes
+	Chains  []string `json:"chains"` // Registered chains that use the address's prefix
+}
@@ -0,0 +1,19 @@
+package types
+
+// Logo returns the preferred image of the asset. Images designed for the
+// requested theme are favoured. If the asset has no images, the deprecated
+// logo_URIs are used instead.
+func (a AssetElement) Logo(darkMode bool) (Image, bool) {
+	for _, image := range a.Images {
+		if image.Theme != nil && image.Theme.DarkMode != nil && *image.Theme.DarkMode == darkMode {
+			return image, true
+		}
+	}
+	if len(a.Images) > 0 {
+		return a.Images[0], true
+	}
+	if a.LogoURIs != nil {
+		return Image{PNG: a.LogoURIs.PNG, SVG: a.LogoURIs.SVG}, true
+	}
+	return Image{}, false
+}
@@ -1,41 +1,116 @@
+// Code generated by schemagen from assetlist.schema.json. DO NOT EDIT.
+
 package types
 
+import "encoding/json"
+
 // Asset lists are a similar mechanism to allow frontends and other UIs to fetch metadata
 // associated with Cosmos SDK denoms, especially for assets sent over IBC.
 type AssetList struct {
-	Assets  []AssetElement `json:"assets"`
-	ChainID string         `json:"chain_id"`
+	Assets  []AssetElement             `json:"assets"`
+	ChainID string                     `json:"chain_id"`
+	Extra   map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type AssetElement struct {
-	Address     *string            `json:"address,omitempty"`
-	Base        string             `json:"base"`                   // The base unit of the asset. Must be in denom_units.
-	CoingeckoID *string            `json:"coingecko_id,omitempty"` // The coingecko id to fetch asset data from coingecko v3 api. See; https://api.coingecko.com/api/v3/coins/list
-	DenomUnits  []DenomUnitElement `json:"denom_units"`
-	Description *string            `json:"description,omitempty"` // A short description of the asset
-	Display     string             `json:"display"`               // The human friendly unit of the asset. Must be in denom_units.
-	Ibc         *Ibc               `json:"ibc,omitempty"`
-	Kind        *Kind              `json:"kind,omitempty"` // The potential options for type of asset. By default, assumes sdk.coin
-	LogoURIs    *LogoURIs          `json:"logo_URIs,omitempty"`
-	Name        *string            `json:"name,omitempty"`   // The project name of the asset. For example Bitcoin.
-	Symbol      *string            `json:"symbol,omitempty"` // The symbol of an asset. For example BTC.
+	Address             *string                    `json:"address,omitempty"`
+	Base                string                     `json:"base"`                   // The base unit of the asset. Must be in denom_units.
+	CoingeckoID         *string                    `json:"coingecko_id,omitempty"` // The coingecko id to fetch asset data from coingecko v3 api. See https://api.coingecko.com/api/v3/coins/list
+	DenomUnits          []DenomUnitElement         `json:"denom_units"`
+	Description         *string                    `json:"description,omitempty"`          // A short description of the asset
+	Display             string                     `json:"display"`                        // The human friendly unit of the asset. Must be in denom_units.
+	ExtendedDescription *string                    `json:"extended_description,omitempty"` // A long description of the asset
+	Ibc                 *Ibc                       `json:"ibc,omitempty"`                  // [Deprecated] The source of an asset sent over IBC. Superseded by traces
+	Images              []Image                    `json:"images,omitempty"`
+	Keywords            []string                   `json:"keywords,omitempty"`
+	Kind                *Kind                      `json:"kind,omitempty"` // The potential options for type of asset. By default, assumes sdk.coin
+	LogoURIs            *LogoURIs                  `json:"logo_URIs,omitempty"`
+	Name                *string                    `json:"name,omitempty"` // The project name of the asset. For example Bitcoin.
+	Socials             *Socials                   `json:"socials,omitempty"`
+	Symbol              *string                    `json:"symbol,omitempty"`     // The symbol of an asset. For example BTC.
+	Traces              []Trace                    `json:"traces,omitempty"`     // The origin of the asset, starting with the index, and capturing all transitions in form and location.
+	TypeAsset           *TypeAsset                 `json:"type_asset,omitempty"` // The type of asset. Supersedes kind
+	Extra               map[string]json.RawMessage `json:"-"`                    // Fields that are not part of the schema
 }
 
 type DenomUnitElement struct {
-	Aliases  []string `json:"aliases,omitempty"`
-	Denom    string   `json:"denom"`
-	Exponent int64    `json:"exponent"`
+	Aliases  []string                   `json:"aliases,omitempty"`
+	Denom    string                     `json:"denom"`
+	Exponent int64                      `json:"exponent"`
+	Extra    map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
+// [Deprecated] The source of an asset sent over IBC. Superseded by traces
 type Ibc struct {
-	DstChannel    string `json:"dst_channel"`
-	SourceChannel string `json:"source_channel"`
-	SourceDenom   string `json:"source_denom"`
+	DstChannel    string                     `json:"dst_channel"`
+	SourceChannel string                     `json:"source_channel"`
+	SourceDenom   string                     `json:"source_denom"`
+	Extra         map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type Image struct {
+	ImageSync *ImageSync                 `json:"image_sync,omitempty"` // The location of the original image, from which this image is synced
+	PNG       *string                    `json:"png,omitempty"`
+	SVG       *string                    `json:"svg,omitempty"`
+	Theme     *ImageTheme                `json:"theme,omitempty"`
+	Extra     map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+// The location of the original image, from which this image is synced
+type ImageSync struct {
+	BaseDenom string                     `json:"base_denom"`
+	ChainName string                     `json:"chain_name"`
+	Extra     map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type ImageTheme struct {
+	Circle          *bool                      `json:"circle,omitempty"`    // Whether the image is circular
+	DarkMode        *bool                      `json:"dark_mode,omitempty"` // Whether the image is intended for a dark background
+	PrimaryColorHex *string                    `json:"primary_color_hex,omitempty"`
+	Extra           map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type LogoURIs struct {
-	PNG *string `json:"png,omitempty"`
-	SVG *string `json:"svg,omitempty"`
+	PNG   *string                    `json:"png,omitempty"`
+	SVG   *string                    `json:"svg,omitempty"`
+	Extra map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type Socials struct {
+	Discord  *string                    `json:"discord,omitempty"`
+	Github   *string                    `json:"github,omitempty"`
+	Medium   *string                    `json:"medium,omitempty"`
+	Reddit   *string                    `json:"reddit,omitempty"`
+	Telegram *string                    `json:"telegram,omitempty"`
+	Twitter  *string                    `json:"twitter,omitempty"`
+	Website  *string                    `json:"website,omitempty"`
+	Extra    map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+// A transition of the asset from one chain or form to another
+type Trace struct {
+	Chain        *TraceChain                `json:"chain,omitempty"`
+	Counterparty Counterparty               `json:"counterparty"`
+	Provider     *string                    `json:"provider,omitempty"` // The entity offering the service (non-IBC transitions)
+	Type         TraceType                  `json:"type"`
+	Extra        map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type TraceChain struct {
+	ChannelID *string                    `json:"channel_id,omitempty"` // The chain's IBC transfer channel (ibc and ibc-cw20 transitions)
+	Contract  *string                    `json:"contract,omitempty"`   // The contract address where the transition takes place (non-IBC transitions)
+	Path      *string                    `json:"path,omitempty"`       // The port/channel/denom input string that generates the 'ibc/...' denom
+	Port      *string                    `json:"port,omitempty"`       // The port used to transfer IBC assets (ibc-cw20 transitions)
+	Extra     map[string]json.RawMessage `json:"-"`                    // Fields that are not part of the schema
+}
+
+type Counterparty struct {
+	BaseDenom string                     `json:"base_denom"`           // The base unit of the asset on its source platform
+	ChainName string                     `json:"chain_name"`           // The name of the counterparty chain
+	ChannelID *string                    `json:"channel_id,omitempty"` // The counterparty IBC channel (ibc and ibc-cw20 transitions)
+	Contract  *string                    `json:"contract,omitempty"`   // The contract address where the transition takes place (non-IBC transitions)
+	Port      *string                    `json:"port,omitempty"`       // The port used to transfer IBC assets, often 'transfer' (ibc-cw20 transitions)
+	Extra     map[string]json.RawMessage `json:"-"`                    // Fields that are not part of the schema
 }
 
 // The potential options for type of asset. By default, assumes sdk.coin
@@ -47,3 +122,213 @@ const (
 	SDKCoin Kind = "sdk.coin"
 	Snip20  Kind = "snip20"
 )
+
+type TraceType string
+
+const (
+	TraceTypeAdditionalMintage TraceType = "additional-mintage"
+	TraceTypeBridge            TraceType = "bridge"
+	TraceTypeIbc               TraceType = "ibc"
+	TraceTypeIbcCw20           TraceType = "ibc-cw20"
+	TraceTypeLegacyMintage     TraceType = "legacy-mintage"
+	TraceTypeLiquidStake       TraceType = "liquid-stake"
+	TraceTypeSynthetic         TraceType = "synthetic"
+	TraceTypeTestMintage       TraceType = "test-mintage"
+	TraceTypeWrapped           TraceType = "wrapped"
+)
+
+// The type of asset. Supersedes kind
+type TypeAsset string
+
+const (
+	TypeAssetBitcoinLike TypeAsset = "bitcoin-like"
+	TypeAssetCw20        TypeAsset = "cw20"
+	TypeAssetErc20       TypeAsset = "erc20"
+	TypeAssetEvmBase     TypeAsset = "evm-base"
+	TypeAssetIcs20       TypeAsset = "ics20"
+	TypeAssetSDKCoin     TypeAsset = "sdk.coin"
+	TypeAssetSnip20      TypeAsset = "snip20"
+	TypeAssetSnip25      TypeAsset = "snip25"
+	TypeAssetSubstrate   TypeAsset = "substrate"
+	TypeAssetSvmBase     TypeAsset = "svm-base"
+)
+
+func (a *AssetList) UnmarshalJSON(data []byte) error {
+	type plain AssetList
+	extra, err := unmarshalWithExtra(data, (*plain)(a), "assets", "chain_id")
+	if err != nil {
+		return err
+	}
+	a.Extra = extra
+	return nil
+}
+
+func (a AssetList) MarshalJSON() ([]byte, error) {
+	type plain AssetList
+	return marshalWithExtra(plain(a), a.Extra)
+}
+
+func (a *AssetElement) UnmarshalJSON(data []byte) error {
+	type plain AssetElement
+	extra, err := unmarshalWithExtra(data, (*plain)(a), "address", "base", "coingecko_id", "denom_units", "description", "display", "extended_description", "ibc", "images", "keywords", "kind", "logo_URIs", "name", "socials", "symbol", "traces", "type_asset")
+	if err != nil {
+		return err
+	}
+	a.Extra = extra
+	return nil
+}
+
+func (a AssetElement) MarshalJSON() ([]byte, error) {
+	type plain AssetElement
+	return marshalWithExtra(plain(a), a.Extra)
+}
+
+func (d *DenomUnitElement) UnmarshalJSON(data []byte) error {
+	type plain DenomUnitElement
+	extra, err := unmarshalWithExtra(data, (*plain)(d), "aliases", "denom", "exponent")
+	if err != nil {
+		return err
+	}
+	d.Extra = extra
+	return nil
+}
+
+func (d DenomUnitElement) MarshalJSON() ([]byte, error) {
+	type plain DenomUnitElement
+	return marshalWithExtra(plain(d), d.Extra)
+}
+
+func (i *Ibc) UnmarshalJSON(data []byte) error {
+	type plain Ibc
+	extra, err := unmarshalWithExtra(data, (*plain)(i), "dst_channel", "source_channel", "source_denom")
+	if err != nil {
+		return err
+	}
+	i.Extra = extra
+	return nil
+}
+
+func (i Ibc) MarshalJSON() ([]byte, error) {
+	type plain Ibc
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+func (i *Image) UnmarshalJSON(data []byte) error {
+	type plain Image
+	extra, err := unmarshalWithExtra(data, (*plain)(i), "image_sync", "png", "svg", "theme")
+	if err != nil {
+		return err
+	}
+	i.Extra = extra
+	return nil
+}
+
+func (i Image) MarshalJSON() ([]byte, error) {
+	type plain Image
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+func (i *ImageSync) UnmarshalJSON(data []byte) error {
+	type plain ImageSync
+	extra, err := unmarshalWithExtra(data, (*plain)(i), "base_denom", "chain_name")
+	if err != nil {
+		return err
+	}
+	i.Extra = extra
+	return nil
+}
+
+func (i ImageSync) MarshalJSON() ([]byte, error) {
+	type plain ImageSync
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+func (i *ImageTheme) UnmarshalJSON(data []byte) error {
+	type plain ImageTheme
+	extra, err := unmarshalWithExtra(data, (*plain)(i), "circle", "dark_mode", "primary_color_hex")
+	if err != nil {
+		return err
+	}
+	i.Extra = extra
+	return nil
+}
+
+func (i ImageTheme) MarshalJSON() ([]byte, error) {
+	type plain ImageTheme
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+func (l *LogoURIs) UnmarshalJSON(data []byte) error {
+	type plain LogoURIs
+	extra, err := unmarshalWithExtra(data, (*plain)(l), "png", "svg")
+	if err != nil {
+		return err
+	}
+	l.Extra = extra
+	return nil
+}
+
+func (l LogoURIs) MarshalJSON() ([]byte, error) {
+	type plain LogoURIs
+	return marshalWithExtra(plain(l), l.Extra)
+}
+
+func (s *Socials) UnmarshalJSON(data []byte) error {
+	type plain Socials
+	extra, err := unmarshalWithExtra(data, (*plain)(s), "discord", "github", "medium", "reddit", "telegram", "twitter", "website")
+	if err != nil {
+		return err
+	}
+	s.Extra = extra
+	return nil
+}
+
+func (s Socials) MarshalJSON() ([]byte, error) {
+	type plain Socials
+	return marshalWithExtra(plain(s), s.Extra)
+}
+
+func (t *Trace) UnmarshalJSON(data []byte) error {
+	type plain Trace
+	extra, err := unmarshalWithExtra(data, (*plain)(t), "chain", "counterparty", "provider", "type")
+	if err != nil {
+		return err
+	}
+	t.Extra = extra
+	return nil
+}
+
+func (t Trace) MarshalJSON() ([]byte, error) {
+	type plain Trace
+	return marshalWithExtra(plain(t), t.Extra)
+}
+
+func (t *TraceChain) UnmarshalJSON(data []byte) error {
+	type plain TraceChain
+	extra, err := unmarshalWithExtra(data, (*plain)(t), "channel_id", "contract", "path", "port")
+	if err != nil {
+		return err
+	}
+	t.Extra = extra
+	return nil
+}
+
+func (t TraceChain) MarshalJSON() ([]byte, error) {
+	type plain TraceChain
+	return marshalWithExtra(plain(t), t.Extra)
+}
+
+func (c *Counterparty) UnmarshalJSON(data []byte) error {
+	type plain Counterparty
+	extra, err := unmarshalWithExtra(data, (*plain)(c), "base_denom", "chain_name", "channel_id", "contract", "port")
+	if err != nil {
+		return err
+	}
+	c.Extra = extra
+	return nil
+}
+
+func (c Counterparty) MarshalJSON() ([]byte, error) {
+	type plain Counterparty
+	return marshalWithExtra(plain(c), c.Extra)
+}
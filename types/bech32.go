@@ -0,0 +1,200 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A self contained implementation of the bech32 encoding as specified in
+// BIP-173: https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32MaxLength follows the Cosmos SDK which allows addresses longer than the
+// 90 characters specified by BIP-173
+const bech32MaxLength = 1023
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// addressSuffixes are appended to a chain's bech32 prefix for keys other than
+// account addresses
+var addressSuffixes = []string{"valoperpub", "valconspub", "valoper", "valcons", "pub"}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+// Bech32Encode encodes 5 bit data with the human readable part
+func Bech32Encode(hrp string, data []byte) (string, error) {
+	if len(hrp) == 0 {
+		return "", errors.New("empty human readable part")
+	}
+	hrp = strings.ToLower(hrp)
+	values := append(bech32HRPExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+
+	var out strings.Builder
+	out.WriteString(hrp)
+	out.WriteByte('1')
+	for _, d := range data {
+		if d >= 32 {
+			return "", fmt.Errorf("invalid data byte %d", d)
+		}
+		out.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		out.WriteByte(bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+	if out.Len() > bech32MaxLength {
+		return "", fmt.Errorf("encoded string exceeds %d characters", bech32MaxLength)
+	}
+	return out.String(), nil
+}
+
+// Bech32Decode decodes a bech32 string into its human readable part and 5 bit
+// data, verifying the checksum
+func Bech32Decode(s string) (string, []byte, error) {
+	if len(s) > bech32MaxLength {
+		return "", nil, fmt.Errorf("bech32 string exceeds %d characters", bech32MaxLength)
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("bech32 string has mixed case")
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, errors.New("invalid bech32 separator position")
+	}
+	hrp := s[:sep]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, fmt.Errorf("invalid character in human readable part: %q", hrp[i])
+		}
+	}
+
+	data := make([]byte, 0, len(s)-sep-1)
+	for _, c := range s[sep+1:] {
+		idx := strings.IndexRune(bech32Charset, c)
+		if idx < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 character %q", c)
+		}
+		data = append(data, byte(idx))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), data...)) != 1 {
+		return "", nil, errors.New("invalid bech32 checksum")
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// ConvertBits regroups data from one bit width to another, i.e. from the 5 bit
+// groups used by bech32 to bytes
+func ConvertBits(data []byte, from, to uint, pad bool) ([]byte, error) {
+	var (
+		acc  uint32
+		bits uint
+		out  = make([]byte, 0, len(data)*int(from)/int(to)+1)
+		max  = uint32(1<<to) - 1
+	)
+	for _, value := range data {
+		if uint32(value)>>from != 0 {
+			return nil, fmt.Errorf("invalid data range: %d", value)
+		}
+		acc = acc<<from | uint32(value)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&max))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&max))
+		}
+	} else if bits >= from || acc<<(to-bits)&max != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	return out, nil
+}
+
+// DecodeAddress decodes a bech32 address into its human readable part and
+// address bytes
+func DecodeAddress(address string) (string, []byte, error) {
+	hrp, data, err := Bech32Decode(address)
+	if err != nil {
+		return "", nil, err
+	}
+	bz, err := ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, bz, nil
+}
+
+// EncodeAddress encodes address bytes as a bech32 address
+func EncodeAddress(hrp string, bz []byte) (string, error) {
+	data, err := ConvertBits(bz, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return Bech32Encode(hrp, data)
+}
+
+// SplitPrefix separates a human readable part into the chain's bech32 prefix
+// and the suffix used for other key types, i.e. "cosmosvaloper" becomes
+// "cosmos" and "valoper"
+func SplitPrefix(hrp string) (string, string) {
+	for _, suffix := range addressSuffixes {
+		if strings.HasSuffix(hrp, suffix) && len(hrp) > len(suffix) {
+			return strings.TrimSuffix(hrp, suffix), suffix
+		}
+	}
+	return hrp, ""
+}
+
+// ValidateAddress checks that the address is a valid bech32 address of the
+// chain. Validator and public key addresses are also accepted.
+func (c Chain) ValidateAddress(address string) error {
+	hrp, _, err := DecodeAddress(address)
+	if err != nil {
+		return err
+	}
+	if prefix, _ := SplitPrefix(hrp); prefix != c.Bech32Prefix && hrp != c.Bech32Prefix {
+		return fmt.Errorf("address prefix %s does not match %s's prefix %s", hrp, c.ChainName, c.Bech32Prefix)
+	}
+	return nil
+}
+
+// ConvertAddress re-encodes an address from any chain with the chain's bech32
+// prefix, retaining any key type suffix such as "valoper"
+func (c Chain) ConvertAddress(address string) (string, error) {
+	hrp, bz, err := DecodeAddress(address)
+	if err != nil {
+		return "", err
+	}
+	_, suffix := SplitPrefix(hrp)
+	return EncodeAddress(c.Bech32Prefix+suffix, bz)
+}
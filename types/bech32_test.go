@@ -0,0 +1,127 @@
+package types
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBech32Decode(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		valid bool
+	}{
+		// valid test vectors from BIP-173
+		{"uppercase", "A12UEL5L", true},
+		{"lowercase", "a12uel5l", true},
+		{"83 character hrp", "an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs", true},
+		{"every data character", "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", true},
+		{"hrp of 1", "11" + strings.Repeat("q", 82) + "c8247j", true},
+		{"last separator", "split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", true},
+		{"symbol hrp", "?1ezyfcl", true},
+		// BIP-173 limits strings to 90 characters but the Cosmos SDK allows
+		// longer addresses
+		{"84 character hrp", "an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx", true},
+
+		// invalid test vectors from BIP-173
+		{"hrp character below range", "\x201nwldj5", false},
+		{"hrp character DEL", "\x7f1axkwrx", false},
+		{"hrp character above range", "\x801eym55h", false},
+		{"no separator", "pzry9x0s0muk", false},
+		{"empty hrp", "1pzry9x0s0muk", false},
+		{"invalid data character", "x1b4n0q5v", false},
+		{"checksum too short", "li1dgmt3", false},
+		{"invalid checksum character", "de1lg7wt\xff", false},
+		{"checksum of uppercase hrp", "A1G7SGD8", false},
+		{"empty hrp with data", "10a06t8", false},
+		{"empty hrp with checksum", "1qzzfhee", false},
+
+		{"invalid checksum", "a12uel5m", false},
+		{"mixed case", "A12uEL5L", false},
+		{"too long", "a1" + strings.Repeat("q", bech32MaxLength), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hrp, data, err := Bech32Decode(tc.input)
+			if !tc.valid {
+				if err == nil {
+					t.Fatalf("expected %q to be invalid", tc.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected %q to be valid: %v", tc.input, err)
+			}
+			// encoding the decoded data gives back the lowercase string
+			encoded, err := Bech32Encode(hrp, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if encoded != strings.ToLower(tc.input) {
+				t.Fatalf("expected %q, got %q", strings.ToLower(tc.input), encoded)
+			}
+		})
+	}
+}
+
+func TestConvertAddress(t *testing.T) {
+	bz := make([]byte, 20)
+	for idx := range bz {
+		bz[idx] = byte(idx)
+	}
+	cosmos := Chain{ChainName: "cosmoshub", Bech32Prefix: "cosmos"}
+	osmosis := Chain{ChainName: "osmosis", Bech32Prefix: "osmo"}
+
+	for _, hrp := range []string{"cosmos", "cosmosvaloper", "cosmosvalconspub"} {
+		t.Run(hrp, func(t *testing.T) {
+			address, err := EncodeAddress(hrp, bz)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cosmos.ValidateAddress(address); err != nil {
+				t.Fatal(err)
+			}
+
+			converted, err := osmosis.ConvertAddress(address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := osmosis.ValidateAddress(converted); err != nil {
+				t.Fatal(err)
+			}
+			if err := cosmos.ValidateAddress(converted); err == nil {
+				t.Fatalf("expected %s to be rejected by cosmoshub", converted)
+			}
+			convertedHRP, convertedBz, err := DecodeAddress(converted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "osmo" + strings.TrimPrefix(hrp, "cosmos"); convertedHRP != want {
+				t.Fatalf("expected prefix %s, got %s", want, convertedHRP)
+			}
+			if !bytes.Equal(convertedBz, bz) {
+				t.Fatalf("expected address bytes %x, got %x", bz, convertedBz)
+			}
+
+			roundTrip, err := cosmos.ConvertAddress(converted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if roundTrip != address {
+				t.Fatalf("expected %s, got %s", address, roundTrip)
+			}
+		})
+	}
+
+	address, err := EncodeAddress("cosmos", bz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// replace the last checksum character
+	last := strings.IndexByte(bech32Charset, address[len(address)-1])
+	corrupted := address[:len(address)-1] + string(bech32Charset[(last+1)%len(bech32Charset)])
+	if _, err := osmosis.ConvertAddress(corrupted); err == nil {
+		t.Fatalf("expected %s with an invalid checksum to be rejected", corrupted)
+	}
+}
@@ -0,0 +1,158 @@
+package types
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Binary is a downloadable release artifact of a chain's daemon for a single
+// platform.
+type Binary struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	URL  string `json:"url"`
+	// Checksum of the artifact in the form "type:value" i.e. "sha256:abc...".
+	// Empty if the registry does not provide one.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// ChecksumType returns the hashing algorithm of the checksum i.e. "sha256"
+func (b Binary) ChecksumType() string {
+	checksumType, _, _ := strings.Cut(b.Checksum, ":")
+	return checksumType
+}
+
+// ChecksumValue returns the hex encoded checksum without the type prefix
+func (b Binary) ChecksumValue() string {
+	_, value, _ := strings.Cut(b.Checksum, ":")
+	return value
+}
+
+// legacyLinuxAmd64 is the key older registry entries use for linux/amd64
+const legacyLinuxAmd64 = "linux/amd"
+
+// List returns all binaries that the registry provides with checksums parsed
+// out of their urls. Platforms outside the schema, such as "linux/riscv64",
+// follow the known ones, sorted by os and arch.
+func (b Binaries) List() []Binary {
+	linuxAmd64 := b.LinuxAmd64
+	if linuxAmd64 == nil {
+		var legacy string
+		if err := json.Unmarshal(b.Extra[legacyLinuxAmd64], &legacy); err == nil {
+			linuxAmd64 = &legacy
+		}
+	}
+	platforms := []struct {
+		os, arch string
+		url      *string
+	}{
+		{"darwin", "amd64", b.DarwinAmd64},
+		{"darwin", "arm64", b.DarwinArm64},
+		{"linux", "amd64", linuxAmd64},
+		{"linux", "arm64", b.LinuxArm64},
+		{"windows", "amd64", b.WindowsAmd64},
+		{"windows", "arm64", b.WindowsArm64},
+	}
+	list := make([]Binary, 0, len(platforms))
+	for _, platform := range platforms {
+		if platform.url == nil {
+			continue
+		}
+		list = append(list, ParseBinary(platform.os, platform.arch, *platform.url))
+	}
+
+	listed := make(map[string]bool, len(list))
+	for _, binary := range list {
+		listed[binary.OS+"/"+binary.Arch] = true
+	}
+	// keys are visited in order so that aliases of the same platform, such as
+	// "linux/x86_64" and "linux/amd", resolve deterministically
+	keys := make([]string, 0, len(b.Extra))
+	for key := range b.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	extra := make([]Binary, 0)
+	for _, key := range keys {
+		os, arch, ok := strings.Cut(key, "/")
+		if !ok || key == legacyLinuxAmd64 || os == "" || arch == "" || strings.Contains(arch, "/") {
+			continue
+		}
+		var rawURL string
+		if err := json.Unmarshal(b.Extra[key], &rawURL); err != nil || rawURL == "" {
+			continue
+		}
+		os, arch = strings.ToLower(os), NormalizeArch(arch)
+		if listed[os+"/"+arch] {
+			continue
+		}
+		listed[os+"/"+arch] = true
+		extra = append(extra, ParseBinary(os, arch, rawURL))
+	}
+	sort.Slice(extra, func(i, j int) bool {
+		if extra[i].OS != extra[j].OS {
+			return extra[i].OS < extra[j].OS
+		}
+		return extra[i].Arch < extra[j].Arch
+	})
+	return append(list, extra...)
+}
+
+// Binary returns the binary for a given operating system and architecture.
+// Common aliases such as "x86_64" or "aarch64" are accepted.
+func (b Binaries) Binary(os, arch string) (Binary, bool) {
+	os, arch = strings.ToLower(os), NormalizeArch(arch)
+	for _, binary := range b.List() {
+		if binary.OS == os && binary.Arch == arch {
+			return binary, true
+		}
+	}
+	return Binary{}, false
+}
+
+// ParseBinary separates the "?checksum=" query suffix that the registry uses
+// from the url of a binary. The rest of the query is kept verbatim so that
+// signed urls remain valid.
+func ParseBinary(os, arch, rawURL string) Binary {
+	binary := Binary{OS: os, Arch: arch, URL: rawURL}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return binary
+	}
+	var (
+		checksum string
+		params   []string
+	)
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		key, value, _ := strings.Cut(param, "=")
+		if key != "checksum" {
+			params = append(params, param)
+			continue
+		}
+		if value, err := url.QueryUnescape(value); err == nil && checksum == "" {
+			checksum = value
+		}
+	}
+	if checksum == "" {
+		return binary
+	}
+	u.RawQuery = strings.Join(params, "&")
+	binary.URL = u.String()
+	binary.Checksum = checksum
+	return binary
+}
+
+// NormalizeArch maps common architecture aliases to the names used by the
+// registry
+func NormalizeArch(arch string) string {
+	switch arch = strings.ToLower(arch); arch {
+	case "x86_64", "x64", "amd":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	default:
+		return arch
+	}
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseBinary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rawURL   string
+		url      string
+		checksum string
+	}{
+		{"no query", "https://example.com/gaiad", "https://example.com/gaiad", ""},
+		{"checksum", "https://example.com/gaiad?checksum=sha256:abc123", "https://example.com/gaiad", "sha256:abc123"},
+		{"escaped checksum", "https://example.com/gaiad?checksum=sha256%3Aabc123", "https://example.com/gaiad", "sha256:abc123"},
+		{"other parameters are kept", "https://example.com/gaiad?X-Amz-Signature=a%2Fb&checksum=sha256:abc123&token=x",
+			"https://example.com/gaiad?X-Amz-Signature=a%2Fb&token=x", "sha256:abc123"},
+		{"first checksum wins", "https://example.com/gaiad?checksum=sha256:abc&checksum=md5:def", "https://example.com/gaiad", "sha256:abc"},
+		{"query without a checksum", "https://example.com/gaiad?token=x", "https://example.com/gaiad?token=x", ""},
+		{"empty checksum", "https://example.com/gaiad?checksum=", "https://example.com/gaiad?checksum=", ""},
+		{"invalid url", "https://exa mple.com/gaiad?checksum=sha256:abc", "https://exa mple.com/gaiad?checksum=sha256:abc", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			binary := ParseBinary("linux", "amd64", tc.rawURL)
+			expected := Binary{OS: "linux", Arch: "amd64", URL: tc.url, Checksum: tc.checksum}
+			if binary != expected {
+				t.Fatalf("expected %+v, got %+v", expected, binary)
+			}
+		})
+	}
+
+	binary := ParseBinary("linux", "amd64", "https://example.com/gaiad?checksum=sha256:abc123")
+	if binary.ChecksumType() != "sha256" || binary.ChecksumValue() != "abc123" {
+		t.Fatalf("unexpected checksum parts %q, %q", binary.ChecksumType(), binary.ChecksumValue())
+	}
+}
+
+func TestBinariesList(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected []string // os/arch of each binary in order
+	}{
+		{"none", `{}`, []string{}},
+		{"known platforms", `{"linux/arm64":"l","darwin/amd64":"d","linux/amd64":"a"}`,
+			[]string{"darwin/amd64", "linux/amd64", "linux/arm64"}},
+		{"legacy linux/amd64", `{"linux/amd":"a"}`, []string{"linux/amd64"}},
+		{"legacy key ignored", `{"linux/amd64":"a","linux/amd":"b"}`, []string{"linux/amd64"}},
+		{"other platforms", `{"linux/amd64":"a","linux/riscv64":"r","freebsd/amd64":"f","linux/386":"i"}`,
+			[]string{"linux/amd64", "freebsd/amd64", "linux/386", "linux/riscv64"}},
+		{"aliases", `{"Linux/x86_64":"a","linux/aarch64":"b"}`, []string{"linux/amd64", "linux/arm64"}},
+		{"aliases of known platforms", `{"linux/amd64":"a","linux/x86_64":"b"}`, []string{"linux/amd64"}},
+		{"not a platform", `{"linux/amd64":"a","checksums":"c","linux/":"x","/amd64":"x","linux/arm/v7":"x","linux/mips":1,"linux/ppc64":""}`,
+			[]string{"linux/amd64"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var binaries Binaries
+			if err := json.Unmarshal([]byte(tc.data), &binaries); err != nil {
+				t.Fatal(err)
+			}
+			platforms := make([]string, 0)
+			for _, binary := range binaries.List() {
+				platforms = append(platforms, binary.OS+"/"+binary.Arch)
+			}
+			if !reflect.DeepEqual(platforms, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, platforms)
+			}
+		})
+	}
+
+	var binaries Binaries
+	if err := json.Unmarshal([]byte(`{"linux/x86_64":"a","linux/amd":"b","linux/riscv64":"https://example.com/gaiad?checksum=sha256:abc"}`), &binaries); err != nil {
+		t.Fatal(err)
+	}
+	if binary, ok := binaries.Binary("linux", "amd64"); !ok || binary.URL != "b" {
+		t.Fatalf("expected the legacy linux/amd64 binary, got %+v, %v", binary, ok)
+	}
+	binary, ok := binaries.Binary("Linux", "riscv64")
+	if !ok || binary.URL != "https://example.com/gaiad" || binary.Checksum != "sha256:abc" {
+		t.Fatalf("expected the riscv64 binary, got %+v, %v", binary, ok)
+	}
+}
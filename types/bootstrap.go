@@ -0,0 +1,18 @@
+package types
+
+// Bootstrap bundles everything an operator needs to stand up a node for a
+// chain. It is derived from the chain's chain.json.
+type Bootstrap struct {
+	ChainName          string `json:"chain_name"`
+	ChainID            string `json:"chain_id"`
+	DaemonName         string `json:"daemon_name,omitempty"`
+	NodeHome           string `json:"node_home,omitempty"`
+	GitRepo            string `json:"git_repo,omitempty"`
+	RecommendedVersion string `json:"recommended_version,omitempty"`
+	BinaryURL          string `json:"binary_url,omitempty"`
+	BinaryChecksum     string `json:"binary_checksum,omitempty"` // in the form "type:value"
+	GenesisURL         string `json:"genesis_url,omitempty"`
+	Seeds              string `json:"seeds"`            // comma separated as expected by config.toml
+	PersistentPeers    string `json:"persistent_peers"` // comma separated as expected by config.toml
+	MinimumGasPrices   string `json:"minimum_gas_prices"`
+}
@@ -1,78 +1,206 @@
+// Code generated by schemagen from chain.schema.json. DO NOT EDIT.
+
 package types
 
+import "encoding/json"
+
 // Cosmos Chain.json is a metadata file that contains information about a cosmos sdk based
 // chain.
 type Chain struct {
-	Apis         *Apis             `json:"apis,omitempty"`
-	Bech32Prefix string            `json:"bech32_prefix"`
-	ChainID      string            `json:"chain_id"`
-	ChainName    string            `json:"chain_name"`
-	Codebase     *Codebase         `json:"codebase,omitempty"`
-	DaemonName   *string           `json:"daemon_name,omitempty"`
-	Explorers    []ExplorerElement `json:"explorers,omitempty"`
-	Fees         *Fees             `json:"fees,omitempty"`
-	Genesis      *Genesis          `json:"genesis,omitempty"`
-	KeyAlgos     []KeyAlgo         `json:"key_algos,omitempty"`
-	NetworkType  *NetworkType      `json:"network_type,omitempty"`
-	NodeHome     *string           `json:"node_home,omitempty"`
-	Peers        *Peers            `json:"peers,omitempty"`
-	PrettyName   *string           `json:"pretty_name,omitempty"`
-	Slip44       *float64          `json:"slip44,omitempty"`
-	Status       *Status           `json:"status,omitempty"`
+	AlternativeSlip44s []float64                  `json:"alternative_slip44s,omitempty"`
+	Apis               *Apis                      `json:"apis,omitempty"`
+	Bech32Prefix       string                     `json:"bech32_prefix"`
+	ChainID            string                     `json:"chain_id"`
+	ChainName          string                     `json:"chain_name"`
+	Codebase           *Codebase                  `json:"codebase,omitempty"`
+	DaemonName         *string                    `json:"daemon_name,omitempty"`
+	Description        *string                    `json:"description,omitempty"`
+	Explorers          []ExplorerElement          `json:"explorers,omitempty"`
+	ExtraCodecs        []ExtraCodec               `json:"extra_codecs,omitempty"`
+	Fees               *Fees                      `json:"fees,omitempty"`
+	Genesis            *Genesis                   `json:"genesis,omitempty"`
+	KeyAlgos           []KeyAlgo                  `json:"key_algos,omitempty"`
+	Keywords           []string                   `json:"keywords,omitempty"`
+	LogoURIs           *LogoURIs                  `json:"logo_URIs,omitempty"`
+	NetworkType        *NetworkType               `json:"network_type,omitempty"`
+	NodeHome           *string                    `json:"node_home,omitempty"`
+	Peers              *Peers                     `json:"peers,omitempty"`
+	PrettyName         *string                    `json:"pretty_name,omitempty"`
+	Slip44             *float64                   `json:"slip44,omitempty"`
+	Staking            *Staking                   `json:"staking,omitempty"`
+	Status             *Status                    `json:"status,omitempty"`
+	Updatelink         *string                    `json:"updatelink,omitempty"` // Link to a json file that is used to update the chain.json file
+	Website            *string                    `json:"website,omitempty"`
+	Extra              map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type Apis struct {
-	Grpc []GrpcElement `json:"grpc,omitempty"`
-	REST []GrpcElement `json:"rest,omitempty"`
-	RPC  []GrpcElement `json:"rpc,omitempty"`
+	EvmHTTPJsonrpc []GrpcElement              `json:"evm-http-jsonrpc,omitempty"`
+	Grpc           []GrpcElement              `json:"grpc,omitempty"`
+	GrpcWeb        []GrpcElement              `json:"grpc-web,omitempty"`
+	REST           []GrpcElement              `json:"rest,omitempty"`
+	RPC            []GrpcElement              `json:"rpc,omitempty"`
+	Wss            []GrpcElement              `json:"wss,omitempty"`
+	Extra          map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type GrpcElement struct {
-	Address  string  `json:"address"`
-	Provider *string `json:"provider,omitempty"`
+	Address  string                     `json:"address"`
+	Archive  *bool                      `json:"archive,omitempty"`
+	Provider *string                    `json:"provider,omitempty"`
+	Extra    map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type Codebase struct {
-	Binaries           *Binaries `json:"binaries,omitempty"`
-	CompatibleVersions []string  `json:"compatible_versions"`
-	GitRepo            string    `json:"git_repo"`
-	RecommendedVersion string    `json:"recommended_version"`
+	Binaries           *Binaries                  `json:"binaries,omitempty"`
+	CompatibleVersions []string                   `json:"compatible_versions"`
+	Consensus          *Consensus                 `json:"consensus,omitempty"`
+	CosmosSDKVersion   *string                    `json:"cosmos_sdk_version,omitempty"`
+	CosmwasmEnabled    *bool                      `json:"cosmwasm_enabled,omitempty"`
+	CosmwasmPath       *string                    `json:"cosmwasm_path,omitempty"` // Relative path to the cosmwasm directory. ex. $HOME/.juno/data/wasm
+	CosmwasmVersion    *string                    `json:"cosmwasm_version,omitempty"`
+	Genesis            *CodebaseGenesis           `json:"genesis,omitempty"`
+	GitRepo            string                     `json:"git_repo"`
+	IbcGoVersion       *string                    `json:"ibc_go_version,omitempty"`
+	IcsEnabled         []ICSEnabled               `json:"ics_enabled,omitempty"` // IBC app or ICS standards.
+	RecommendedVersion string                     `json:"recommended_version"`
+	TendermintVersion  *string                    `json:"tendermint_version,omitempty"`
+	Versions           []Version                  `json:"versions,omitempty"`
+	Extra              map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type Binaries struct {
-	LinuxAMD *string `json:"linux/amd,omitempty"`
+	DarwinAmd64  *string                    `json:"darwin/amd64,omitempty"`
+	DarwinArm64  *string                    `json:"darwin/arm64,omitempty"`
+	LinuxAmd64   *string                    `json:"linux/amd64,omitempty"`
+	LinuxArm64   *string                    `json:"linux/arm64,omitempty"`
+	WindowsAmd64 *string                    `json:"windows/amd64,omitempty"`
+	WindowsArm64 *string                    `json:"windows/arm64,omitempty"`
+	Extra        map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type Consensus struct {
+	Type    ConsensusType              `json:"type"`
+	Version *string                    `json:"version,omitempty"`
+	Extra   map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type CodebaseGenesis struct {
+	GenesisURL string                     `json:"genesis_url"`
+	Name       *string                    `json:"name,omitempty"`
+	Extra      map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type Version struct {
+	Binaries            *Binaries                  `json:"binaries,omitempty"`
+	CompatibleVersions  []string                   `json:"compatible_versions,omitempty"`
+	Consensus           *Consensus                 `json:"consensus,omitempty"`
+	CosmosSDKVersion    *string                    `json:"cosmos_sdk_version,omitempty"`
+	CosmwasmEnabled     *bool                      `json:"cosmwasm_enabled,omitempty"`
+	CosmwasmPath        *string                    `json:"cosmwasm_path,omitempty"` // Relative path to the cosmwasm directory. ex. $HOME/.juno/data/wasm
+	CosmwasmVersion     *string                    `json:"cosmwasm_version,omitempty"`
+	Height              *float64                   `json:"height,omitempty"` // Block Height
+	IbcGoVersion        *string                    `json:"ibc_go_version,omitempty"`
+	IcsEnabled          []ICSEnabled               `json:"ics_enabled,omitempty"`           // IBC app or ICS standards.
+	Name                string                     `json:"name"`                            // Official Upgrade Name
+	NextVersionName     *string                    `json:"next_version_name,omitempty"`     // [Optional] Name of the following version
+	PreviousVersionName *string                    `json:"previous_version_name,omitempty"` // [Optional] Name of the previous version
+	Proposal            *float64                   `json:"proposal,omitempty"`              // Proposal that will officially signal community acceptance of the upgrade.
+	RecommendedVersion  *string                    `json:"recommended_version,omitempty"`
+	Tag                 *string                    `json:"tag,omitempty"` // Git Upgrade Tag
+	TendermintVersion   *string                    `json:"tendermint_version,omitempty"`
+	Extra               map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type ExplorerElement struct {
-	Kind   *string `json:"kind,omitempty"`
-	TxPage *string `json:"tx_page,omitempty"`
-	URL    *string `json:"url,omitempty"`
+	AccountPage *string                    `json:"account_page,omitempty"`
+	Kind        *string                    `json:"kind,omitempty"`
+	TxPage      *string                    `json:"tx_page,omitempty"`
+	URL         *string                    `json:"url,omitempty"`
+	Extra       map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type Fees struct {
-	FeeTokens []FeeTokenElement `json:"fee_tokens,omitempty"`
+	FeeTokens []FeeTokenElement          `json:"fee_tokens,omitempty"`
+	Extra     map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type FeeTokenElement struct {
-	Denom            string   `json:"denom"`
-	FixedMinGasPrice *float64 `json:"fixed_min_gas_price,omitempty"`
+	AverageGasPrice  *float64                   `json:"average_gas_price,omitempty"`
+	Denom            string                     `json:"denom"`
+	FixedMinGasPrice *float64                   `json:"fixed_min_gas_price,omitempty"`
+	GasCosts         *GasCosts                  `json:"gas_costs,omitempty"`
+	HighGasPrice     *float64                   `json:"high_gas_price,omitempty"`
+	LowGasPrice      *float64                   `json:"low_gas_price,omitempty"`
+	Extra            map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type GasCosts struct {
+	CosmosSend  *float64                   `json:"cosmos_send,omitempty"`
+	IbcTransfer *float64                   `json:"ibc_transfer,omitempty"`
+	Extra       map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type Genesis struct {
-	GenesisURL *string `json:"genesis_url,omitempty"`
+	GenesisURL *string                    `json:"genesis_url,omitempty"`
+	IcsCcvURL  *string                    `json:"ics_ccv_url,omitempty"`
+	TarFile    *string                    `json:"tar_file,omitempty"`
+	Extra      map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type Peers struct {
-	PersistentPeers []PersistentPeerElement `json:"persistent_peers,omitempty"`
-	Seeds           []PersistentPeerElement `json:"seeds,omitempty"`
+	PersistentPeers []PersistentPeerElement    `json:"persistent_peers,omitempty"`
+	Seeds           []PersistentPeerElement    `json:"seeds,omitempty"`
+	Extra           map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
 type PersistentPeerElement struct {
-	Address  string  `json:"address"`
-	ID       string  `json:"id"`
-	Provider *string `json:"provider,omitempty"`
+	Address  string                     `json:"address"`
+	ID       string                     `json:"id"`
+	Provider *string                    `json:"provider,omitempty"`
+	Extra    map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
 }
 
+type Staking struct {
+	LockDuration  *LockDuration              `json:"lock_duration,omitempty"`
+	StakingTokens []StakingToken             `json:"staking_tokens"`
+	Extra         map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type LockDuration struct {
+	Blocks *float64                   `json:"blocks,omitempty"` // The number of blocks for which the staked tokens are locked.
+	Time   *string                    `json:"time,omitempty"`   // The approximate time for which the staked tokens are locked.
+	Extra  map[string]json.RawMessage `json:"-"`                // Fields that are not part of the schema
+}
+
+type StakingToken struct {
+	Denom string                     `json:"denom"`
+	Extra map[string]json.RawMessage `json:"-"` // Fields that are not part of the schema
+}
+
+type ConsensusType string
+
+const (
+	Cometbft      ConsensusType = "cometbft"
+	SeiTendermint ConsensusType = "sei-tendermint"
+	Tendermint    ConsensusType = "tendermint"
+)
+
+type ICSEnabled string
+
+const (
+	Ics20_1 ICSEnabled = "ics20-1"
+	Ics27_1 ICSEnabled = "ics27-1"
+	Mauth   ICSEnabled = "mauth"
+)
+
+type ExtraCodec string
+
+const (
+	Ethermint ExtraCodec = "ethermint"
+	Injective ExtraCodec = "injective"
+)
+
 type KeyAlgo string
 
 const (
@@ -96,3 +224,273 @@ const (
 	Live     Status = "live"
 	Upcoming Status = "upcoming"
 )
+
+func (c *Chain) UnmarshalJSON(data []byte) error {
+	type plain Chain
+	extra, err := unmarshalWithExtra(data, (*plain)(c), "alternative_slip44s", "apis", "bech32_prefix", "chain_id", "chain_name", "codebase", "daemon_name", "description", "explorers", "extra_codecs", "fees", "genesis", "key_algos", "keywords", "logo_URIs", "network_type", "node_home", "peers", "pretty_name", "slip44", "staking", "status", "updatelink", "website")
+	if err != nil {
+		return err
+	}
+	c.Extra = extra
+	return nil
+}
+
+func (c Chain) MarshalJSON() ([]byte, error) {
+	type plain Chain
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+func (a *Apis) UnmarshalJSON(data []byte) error {
+	type plain Apis
+	extra, err := unmarshalWithExtra(data, (*plain)(a), "evm-http-jsonrpc", "grpc", "grpc-web", "rest", "rpc", "wss")
+	if err != nil {
+		return err
+	}
+	a.Extra = extra
+	return nil
+}
+
+func (a Apis) MarshalJSON() ([]byte, error) {
+	type plain Apis
+	return marshalWithExtra(plain(a), a.Extra)
+}
+
+func (g *GrpcElement) UnmarshalJSON(data []byte) error {
+	type plain GrpcElement
+	extra, err := unmarshalWithExtra(data, (*plain)(g), "address", "archive", "provider")
+	if err != nil {
+		return err
+	}
+	g.Extra = extra
+	return nil
+}
+
+func (g GrpcElement) MarshalJSON() ([]byte, error) {
+	type plain GrpcElement
+	return marshalWithExtra(plain(g), g.Extra)
+}
+
+func (c *Codebase) UnmarshalJSON(data []byte) error {
+	type plain Codebase
+	extra, err := unmarshalWithExtra(data, (*plain)(c), "binaries", "compatible_versions", "consensus", "cosmos_sdk_version", "cosmwasm_enabled", "cosmwasm_path", "cosmwasm_version", "genesis", "git_repo", "ibc_go_version", "ics_enabled", "recommended_version", "tendermint_version", "versions")
+	if err != nil {
+		return err
+	}
+	c.Extra = extra
+	return nil
+}
+
+func (c Codebase) MarshalJSON() ([]byte, error) {
+	type plain Codebase
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+func (b *Binaries) UnmarshalJSON(data []byte) error {
+	type plain Binaries
+	extra, err := unmarshalWithExtra(data, (*plain)(b), "darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64", "windows/amd64", "windows/arm64")
+	if err != nil {
+		return err
+	}
+	b.Extra = extra
+	return nil
+}
+
+func (b Binaries) MarshalJSON() ([]byte, error) {
+	type plain Binaries
+	return marshalWithExtra(plain(b), b.Extra)
+}
+
+func (c *Consensus) UnmarshalJSON(data []byte) error {
+	type plain Consensus
+	extra, err := unmarshalWithExtra(data, (*plain)(c), "type", "version")
+	if err != nil {
+		return err
+	}
+	c.Extra = extra
+	return nil
+}
+
+func (c Consensus) MarshalJSON() ([]byte, error) {
+	type plain Consensus
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+func (c *CodebaseGenesis) UnmarshalJSON(data []byte) error {
+	type plain CodebaseGenesis
+	extra, err := unmarshalWithExtra(data, (*plain)(c), "genesis_url", "name")
+	if err != nil {
+		return err
+	}
+	c.Extra = extra
+	return nil
+}
+
+func (c CodebaseGenesis) MarshalJSON() ([]byte, error) {
+	type plain CodebaseGenesis
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+func (v *Version) UnmarshalJSON(data []byte) error {
+	type plain Version
+	extra, err := unmarshalWithExtra(data, (*plain)(v), "binaries", "compatible_versions", "consensus", "cosmos_sdk_version", "cosmwasm_enabled", "cosmwasm_path", "cosmwasm_version", "height", "ibc_go_version", "ics_enabled", "name", "next_version_name", "previous_version_name", "proposal", "recommended_version", "tag", "tendermint_version")
+	if err != nil {
+		return err
+	}
+	v.Extra = extra
+	return nil
+}
+
+func (v Version) MarshalJSON() ([]byte, error) {
+	type plain Version
+	return marshalWithExtra(plain(v), v.Extra)
+}
+
+func (e *ExplorerElement) UnmarshalJSON(data []byte) error {
+	type plain ExplorerElement
+	extra, err := unmarshalWithExtra(data, (*plain)(e), "account_page", "kind", "tx_page", "url")
+	if err != nil {
+		return err
+	}
+	e.Extra = extra
+	return nil
+}
+
+func (e ExplorerElement) MarshalJSON() ([]byte, error) {
+	type plain ExplorerElement
+	return marshalWithExtra(plain(e), e.Extra)
+}
+
+func (f *Fees) UnmarshalJSON(data []byte) error {
+	type plain Fees
+	extra, err := unmarshalWithExtra(data, (*plain)(f), "fee_tokens")
+	if err != nil {
+		return err
+	}
+	f.Extra = extra
+	return nil
+}
+
+func (f Fees) MarshalJSON() ([]byte, error) {
+	type plain Fees
+	return marshalWithExtra(plain(f), f.Extra)
+}
+
+func (f *FeeTokenElement) UnmarshalJSON(data []byte) error {
+	type plain FeeTokenElement
+	extra, err := unmarshalWithExtra(data, (*plain)(f), "average_gas_price", "denom", "fixed_min_gas_price", "gas_costs", "high_gas_price", "low_gas_price")
+	if err != nil {
+		return err
+	}
+	f.Extra = extra
+	return nil
+}
+
+func (f FeeTokenElement) MarshalJSON() ([]byte, error) {
+	type plain FeeTokenElement
+	return marshalWithExtra(plain(f), f.Extra)
+}
+
+func (g *GasCosts) UnmarshalJSON(data []byte) error {
+	type plain GasCosts
+	extra, err := unmarshalWithExtra(data, (*plain)(g), "cosmos_send", "ibc_transfer")
+	if err != nil {
+		return err
+	}
+	g.Extra = extra
+	return nil
+}
+
+func (g GasCosts) MarshalJSON() ([]byte, error) {
+	type plain GasCosts
+	return marshalWithExtra(plain(g), g.Extra)
+}
+
+func (g *Genesis) UnmarshalJSON(data []byte) error {
+	type plain Genesis
+	extra, err := unmarshalWithExtra(data, (*plain)(g), "genesis_url", "ics_ccv_url", "tar_file")
+	if err != nil {
+		return err
+	}
+	g.Extra = extra
+	return nil
+}
+
+func (g Genesis) MarshalJSON() ([]byte, error) {
+	type plain Genesis
+	return marshalWithExtra(plain(g), g.Extra)
+}
+
+func (p *Peers) UnmarshalJSON(data []byte) error {
+	type plain Peers
+	extra, err := unmarshalWithExtra(data, (*plain)(p), "persistent_peers", "seeds")
+	if err != nil {
+		return err
+	}
+	p.Extra = extra
+	return nil
+}
+
+func (p Peers) MarshalJSON() ([]byte, error) {
+	type plain Peers
+	return marshalWithExtra(plain(p), p.Extra)
+}
+
+func (p *PersistentPeerElement) UnmarshalJSON(data []byte) error {
+	type plain PersistentPeerElement
+	extra, err := unmarshalWithExtra(data, (*plain)(p), "address", "id", "provider")
+	if err != nil {
+		return err
+	}
+	p.Extra = extra
+	return nil
+}
+
+func (p PersistentPeerElement) MarshalJSON() ([]byte, error) {
+	type plain PersistentPeerElement
+	return marshalWithExtra(plain(p), p.Extra)
+}
+
+func (s *Staking) UnmarshalJSON(data []byte) error {
+	type plain Staking
+	extra, err := unmarshalWithExtra(data, (*plain)(s), "lock_duration", "staking_tokens")
+	if err != nil {
+		return err
+	}
+	s.Extra = extra
+	return nil
+}
+
+func (s Staking) MarshalJSON() ([]byte, error) {
+	type plain Staking
+	return marshalWithExtra(plain(s), s.Extra)
+}
+
+func (l *LockDuration) UnmarshalJSON(data []byte) error {
+	type plain LockDuration
+	extra, err := unmarshalWithExtra(data, (*plain)(l), "blocks", "time")
+	if err != nil {
+		return err
+	}
+	l.Extra = extra
+	return nil
+}
+
+func (l LockDuration) MarshalJSON() ([]byte, error) {
+	type plain LockDuration
+	return marshalWithExtra(plain(l), l.Extra)
+}
+
+func (s *StakingToken) UnmarshalJSON(data []byte) error {
+	type plain StakingToken
+	extra, err := unmarshalWithExtra(data, (*plain)(s), "denom")
+	if err != nil {
+		return err
+	}
+	s.Extra = extra
+	return nil
+}
+
+func (s StakingToken) MarshalJSON() ([]byte, error) {
+	type plain StakingToken
+	return marshalWithExtra(plain(s), s.Extra)
+}
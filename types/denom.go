@@ -0,0 +1,117 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+const (
+	// maxDecimalLength bounds the length of a decimal amount, which is ample
+	// for a 256 bit integer with 18 decimal places
+	maxDecimalLength = 128
+	// maxExponentShift bounds the difference between the exponents of two
+	// denom units that an amount is converted between
+	maxExponentShift = 128
+)
+
+// decimalPattern matches plain decimals. Exponents are not accepted as a
+// short string such as "1e999999" would expand to an enormous number.
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Amount is a quantity of a denom. The amount is a decimal string so that no
+// precision is lost.
+type Amount struct {
+	Amount string `json:"amount"`
+	Denom  string `json:"denom"`
+}
+
+// DenomUnit returns the denom unit of the asset that matches the denom or one
+// of its aliases
+func (a AssetElement) DenomUnit(denom string) (DenomUnitElement, bool) {
+	for _, unit := range a.DenomUnits {
+		if unit.Denom == denom {
+			return unit, true
+		}
+	}
+	for _, unit := range a.DenomUnits {
+		for _, alias := range unit.Aliases {
+			if alias == denom {
+				return unit, true
+			}
+		}
+	}
+	return DenomUnitElement{}, false
+}
+
+// Convert converts a decimal amount of the asset from one denom unit to
+// another, i.e. "1500000" "uatom" to "1.5" "atom". Aliases are accepted for
+// both denoms. Arbitrary precision arithmetic is used so the result is exact.
+func (a AssetElement) Convert(amount, fromDenom, toDenom string) (string, error) {
+	from, ok := a.DenomUnit(fromDenom)
+	if !ok {
+		return "", fmt.Errorf("denom %s is not a unit of %s", fromDenom, a.Display)
+	}
+	to, ok := a.DenomUnit(toDenom)
+	if !ok {
+		return "", fmt.Errorf("denom %s is not a unit of %s", toDenom, a.Display)
+	}
+
+	shift := from.Exponent - to.Exponent
+	if shift > maxExponentShift || shift < -maxExponentShift {
+		return "", fmt.Errorf("cannot convert between exponents %d and %d", from.Exponent, to.Exponent)
+	}
+
+	value, err := ParseDecimal(amount)
+	if err != nil {
+		return "", err
+	}
+	return FormatDecimal(ShiftDecimal(value, shift)), nil
+}
+
+// ParseDecimal parses a decimal string such as "1.5" or "-0.001". Exponents,
+// fractions and amounts longer than 128 characters are rejected.
+func ParseDecimal(amount string) (*big.Rat, error) {
+	if len(amount) > maxDecimalLength {
+		return nil, fmt.Errorf("decimal amount exceeds %d characters", maxDecimalLength)
+	}
+	if !decimalPattern.MatchString(amount) {
+		return nil, fmt.Errorf("invalid decimal amount %q", amount)
+	}
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal amount %q", amount)
+	}
+	return value, nil
+}
+
+// ShiftDecimal multiplies value by 10^exponent. The exponent may be negative.
+func ShiftDecimal(value *big.Rat, exponent int64) *big.Rat {
+	abs := exponent
+	if abs < 0 {
+		abs = -abs
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(abs), nil))
+	if exponent < 0 {
+		scale.Inv(scale)
+	}
+	return new(big.Rat).Mul(value, scale)
+}
+
+// FormatDecimal formats a value with as many decimal places as are required
+// to represent it exactly. Values that can't be represented exactly as a
+// decimal are rounded to 64 decimal places.
+func FormatDecimal(value *big.Rat) string {
+	const maxPrecision = 64
+	ten := big.NewInt(10)
+	pow := big.NewInt(1)
+	rem := new(big.Int)
+	for precision := 0; precision <= maxPrecision; precision++ {
+		if rem.Mod(pow, value.Denom()).Sign() == 0 {
+			return value.FloatString(precision)
+		}
+		pow.Mul(pow, ten)
+	}
+	return strings.TrimRight(strings.TrimRight(value.FloatString(maxPrecision), "0"), ".")
+}
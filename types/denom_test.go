@@ -0,0 +1,126 @@
+package types
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string // formatted, empty if the input is invalid
+	}{
+		{"0", "0"},
+		{"1500000", "1500000"},
+		{"1.5", "1.5"},
+		{"-0.001", "-0.001"},
+		{"000.100", "0.1"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935.123456789012345678",
+			"115792089237316195423570985008687907853269984665640564039457584007913129639935.123456789012345678"},
+
+		{"", ""},
+		{"abc", ""},
+		{"1/3", ""},
+		{"1e6", ""},
+		{"1e999999", ""},
+		{"1E-6", ""},
+		{"0x10", ""},
+		{"+1", ""},
+		{"--1", ""},
+		{" 1", ""},
+		{"1.", ""},
+		{".5", ""},
+		{"1.2.3", ""},
+		{"Inf", ""},
+		{strings.Repeat("9", maxDecimalLength+1), ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			value, err := ParseDecimal(tc.input)
+			if tc.expected == "" {
+				if err == nil {
+					t.Fatalf("expected %q to be rejected, got %s", tc.input, value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if formatted := FormatDecimal(value); formatted != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, formatted)
+			}
+		})
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	testCases := []struct {
+		value    *big.Rat
+		expected string
+	}{
+		{big.NewRat(0, 1), "0"},
+		{big.NewRat(3, 2), "1.5"},
+		{big.NewRat(-1, 1000), "-0.001"},
+		{big.NewRat(1, 1000000000000000000), "0.000000000000000001"},
+		{big.NewRat(123456789, 1), "123456789"},
+		// values without an exact decimal representation are rounded to 64
+		// decimal places
+		{big.NewRat(1, 3), "0." + strings.Repeat("3", 64)},
+		{big.NewRat(2, 3), "0." + strings.Repeat("6", 63) + "7"},
+		{big.NewRat(-2, 3), "-0." + strings.Repeat("6", 63) + "7"},
+		{big.NewRat(1, 7), "0.1428571428571428571428571428571428571428571428571428571428571429"},
+	}
+	for _, tc := range testCases {
+		if got := FormatDecimal(tc.value); got != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.value, tc.expected, got)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	asset := AssetElement{
+		Base:    "uatom",
+		Display: "atom",
+		DenomUnits: []DenomUnitElement{
+			{Denom: "uatom", Exponent: 0, Aliases: []string{"microatom"}},
+			{Denom: "matom", Exponent: 3},
+			{Denom: "atom", Exponent: 6},
+			{Denom: "huge", Exponent: 1000000},
+		},
+	}
+	testCases := []struct {
+		amount, from, to string
+		expected         string // empty if the conversion fails
+	}{
+		{"1500000", "uatom", "atom", "1.5"},
+		{"1.5", "atom", "uatom", "1500000"},
+		{"1", "uatom", "atom", "0.000001"},
+		{"0.0000001", "atom", "uatom", "0.1"},
+		{"-2500", "microatom", "matom", "-2.5"},
+		{"42", "atom", "atom", "42"},
+		{"0.000", "atom", "uatom", "0"},
+
+		{"1", "uatom", "btc", ""},
+		{"1", "btc", "uatom", ""},
+		{"1e6", "uatom", "atom", ""},
+		{"1", "uatom", "huge", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.amount+tc.from+"-"+tc.to, func(t *testing.T) {
+			converted, err := asset.Convert(tc.amount, tc.from, tc.to)
+			if tc.expected == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", converted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if converted != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, converted)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package types
+
+// Error codes identify the kind of failure independently of its message
+const (
+	ErrCodeBadRequest       = "bad_request"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeMethodNotAllowed = "method_not_allowed"
+	ErrCodeUnauthorized     = "unauthorized"
+	ErrCodeForbidden        = "forbidden"
+	ErrCodeRateLimited      = "rate_limited"
+	ErrCodeValidation       = "validation_failed"
+	ErrCodeNotReady         = "not_ready"
+	ErrCodeUpstream         = "upstream_error"
+	ErrCodeInternal         = "internal_error"
+)
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Code    string      `json:"code"`              // One of the ErrCode constants
+	Message string      `json:"message"`           // Human readable description of the error
+	Details interface{} `json:"details,omitempty"` // Additional information, i.e. the schema errors of a rejected document
+}
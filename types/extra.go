@@ -0,0 +1,50 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// unmarshalWithExtra decodes data into v and returns all fields of the JSON
+// object that are not in known. This allows fields that are not yet part of
+// the schema to be preserved.
+func unmarshalWithExtra(data []byte, v interface{}, known ...string) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range known {
+		delete(fields, key)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// marshalWithExtra encodes v, adding the extra fields to the JSON object.
+// Fields of v take precedence over extra fields with the same key.
+func marshalWithExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	bz, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return bz, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,17 @@
+package types
+
+// FeeEstimate is the fee for an amount of gas paid in a single fee token at
+// low, average and high gas prices
+type FeeEstimate struct {
+	Denom   string    `json:"denom"`
+	Gas     uint64    `json:"gas"`
+	Low     FeeAmount `json:"low"`
+	Average FeeAmount `json:"average"`
+	High    FeeAmount `json:"high"`
+}
+
+type FeeAmount struct {
+	GasPrice string  `json:"gas_price"`
+	Amount   Amount  `json:"amount"`            // In the fee token's base denom, rounded up
+	Display  *Amount `json:"display,omitempty"` // In the display denom of the asset if it is known
+}
@@ -0,0 +1,7 @@
+package types
+
+// The types in chain.go and assetlist.go are generated from the registry's JSON
+// schemas. To regenerate them after updating a schema run `go generate ./types`.
+
+//go:generate go run ./internal/schemagen -schema chain.schema.json -type Chain -out chain.go -skip LogoURIs -rename defs.endpoint=GrpcElement,defs.peer=PersistentPeerElement,defs.explorer=ExplorerElement,defs.fee_token=FeeTokenElement,key_algos[]=KeyAlgo,extra_codecs[]=ExtraCodec,codebase.genesis=CodebaseGenesis,codebase.ics_enabled[]=ICSEnabled,defs.version.ics_enabled[]=ICSEnabled,defs.consensus.type=ConsensusType,staking.lock_duration=LockDuration
+//go:generate go run ./internal/schemagen -schema assetlist.schema.json -type AssetList -out assetlist.go -rename defs.asset=AssetElement,defs.denom_unit=DenomUnitElement,defs.trace.type=TraceType,defs.trace.chain=TraceChain,defs.image.theme=ImageTheme
@@ -0,0 +1,9 @@
+package types
+
+// GenesisChecksum describes a genesis file cached and served by skychart
+type GenesisChecksum struct {
+	ChainID string `json:"chain_id"`
+	URL     string `json:"url"`    // where the genesis file was originally fetched from
+	SHA256  string `json:"sha256"` // hex encoded checksum of the decompressed genesis file
+	Size    int64  `json:"size"`
+}
@@ -0,0 +1,391 @@
+// Command schemagen generates go types from the chain-registry JSON schemas.
+// It supports the subset of JSON schema used by the registry: objects,
+// arrays, string enums, primitives and local "$ref"s to "$defs".
+//
+// Every generated struct retains fields that are not part of the schema in an
+// Extra map so that they survive a round trip through the types.
+//
+// Usage (see types/generate.go):
+//
+//	schemagen -schema chain.schema.json -type Chain -out chain.go [-rename path=Name,...] [-skip Name,...]
+//
+// Renames are keyed by the path of the node within the schema, i.e.
+// "codebase.genesis", "key_algos[]" or "defs.endpoint", and are used to keep
+// the names of existing types stable.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Description string             `json:"description"`
+	Properties  map[string]*schema `json:"properties"`
+	Required    []string           `json:"required"`
+	Items       *schema            `json:"items"`
+	Enum        []string           `json:"enum"`
+	Defs        map[string]*schema `json:"$defs"`
+}
+
+func main() {
+	schemaPath := flag.String("schema", "", "path to the JSON schema")
+	typeName := flag.String("type", "", "name of the root type")
+	out := flag.String("out", "", "path of the generated go file")
+	renames := flag.String("rename", "", "comma separated list of path=Name overrides")
+	skips := flag.String("skip", "", "comma separated list of type names that are defined elsewhere in the package")
+	flag.Parse()
+	if *schemaPath == "" || *typeName == "" || *out == "" {
+		flag.Usage()
+		log.Fatal("schema, type and out are required")
+	}
+
+	bz, err := ioutil.ReadFile(*schemaPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var root schema
+	if err := json.Unmarshal(bz, &root); err != nil {
+		log.Fatalf("parsing %s: %v", *schemaPath, err)
+	}
+
+	g := &generator{
+		root:    &root,
+		names:   map[string]string{"": *typeName},
+		skip:    make(map[string]bool),
+		decls:   make(map[string]string),
+		consts:  make(map[string]string),
+		structs: make(map[string][]string),
+	}
+	for _, rename := range split(*renames) {
+		parts := strings.SplitN(rename, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("invalid rename %q", rename)
+		}
+		g.names[parts[0]] = parts[1]
+	}
+	for _, name := range split(*skips) {
+		g.skip[name] = true
+	}
+
+	if _, err := g.goType(&root, "", *typeName); err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := format.Source(g.file(filepath.Base(*schemaPath)))
+	if err != nil {
+		log.Fatalf("formatting generated code: %v", err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	root  *schema
+	names map[string]string // schema path -> type name
+	skip  map[string]bool
+
+	order   []string            // type names in the order they were discovered
+	enums   []string            // enum type names, which are rendered after all structs
+	decls   map[string]string   // type name -> declaration
+	consts  map[string]string   // enum constant -> enum type name
+	structs map[string][]string // struct type name -> json keys
+}
+
+// goType returns the go type for a schema node, declaring any named types
+// that it requires along the way
+func (g *generator) goType(s *schema, path, name string) (string, error) {
+	if rename, ok := g.names[path]; ok {
+		name = rename
+	}
+
+	switch {
+	case s.Ref != "":
+		def := strings.TrimPrefix(s.Ref, "#/$defs/")
+		target, ok := g.root.Defs[def]
+		if !ok {
+			return "", fmt.Errorf("%s: unresolved reference %s", path, s.Ref)
+		}
+		return g.goType(target, "defs."+def, camelCase(def))
+
+	case len(s.Enum) > 0:
+		return name, g.declareEnum(s, name)
+
+	case s.Type == "object" && len(s.Properties) > 0:
+		return name, g.declareStruct(s, path, name)
+
+	case s.Type == "object":
+		return "map[string]interface{}", nil
+
+	case s.Type == "array":
+		if s.Items == nil {
+			return "[]interface{}", nil
+		}
+		item, err := g.goType(s.Items, path+"[]", name+"Element")
+		return "[]" + item, err
+
+	case s.Type == "string":
+		return "string", nil
+
+	case s.Type == "integer":
+		return "int64", nil
+
+	case s.Type == "number":
+		return "float64", nil
+
+	case s.Type == "boolean":
+		return "bool", nil
+
+	default:
+		return "interface{}", nil
+	}
+}
+
+func (g *generator) declareStruct(s *schema, path, name string) error {
+	if g.skip[name] {
+		return nil
+	}
+	// reserve the position of the type so that types are ordered top down
+	_, exists := g.decls[name]
+	if !exists {
+		g.order = append(g.order, name)
+		g.decls[name] = ""
+	}
+
+	required := make(map[string]bool)
+	for _, key := range s.Required {
+		required[key] = true
+	}
+
+	type field struct {
+		name, goType, key, comment string
+		omitempty                  bool
+	}
+	// visit fields in the order they are declared so that the output is stable
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return camelCase(keys[i]) < camelCase(keys[j]) })
+
+	fields := make([]field, 0, len(s.Properties))
+	for _, key := range keys {
+		prop := s.Properties[key]
+		fieldName := camelCase(key)
+		fieldType, err := g.goType(prop, join(path, key), fieldName)
+		if err != nil {
+			return err
+		}
+		f := field{name: fieldName, goType: fieldType, key: key, comment: prop.Description}
+		if !required[key] {
+			f.omitempty = true
+			if !strings.HasPrefix(fieldType, "[]") && !strings.HasPrefix(fieldType, "map[") && fieldType != "interface{}" {
+				f.goType = "*" + fieldType
+			}
+		}
+		if f.comment == "" && prop.Ref != "" {
+			f.comment = g.root.Defs[strings.TrimPrefix(prop.Ref, "#/$defs/")].Description
+		}
+		fields = append(fields, f)
+	}
+
+	var buf bytes.Buffer
+	writeComment(&buf, s.Description)
+	fmt.Fprintf(&buf, "type %s struct {\n", name)
+	for _, f := range fields {
+		tag := f.key
+		if f.omitempty {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&buf, "%s %s `json:%q`", f.name, f.goType, tag)
+		if f.comment != "" {
+			fmt.Fprintf(&buf, " // %s", oneLine(f.comment))
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("Extra map[string]json.RawMessage `json:\"-\"` // Fields that are not part of the schema\n")
+	buf.WriteString("}\n")
+
+	return g.declare(name, buf.String(), func() { g.structs[name] = keys })
+}
+
+func (g *generator) declareEnum(s *schema, name string) error {
+	if g.skip[name] {
+		return nil
+	}
+	values := append([]string(nil), s.Enum...)
+	sort.Slice(values, func(i, j int) bool { return camelCase(values[i]) < camelCase(values[j]) })
+
+	// prefix the constants with the type name if any of them would collide
+	// with an existing identifier
+	prefix := ""
+	for _, value := range values {
+		constName := camelCase(value)
+		if owner, ok := g.consts[constName]; (ok && owner != name) || g.decls[constName] != "" {
+			prefix = name
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	writeComment(&buf, s.Description)
+	fmt.Fprintf(&buf, "type %s string\n\nconst (\n", name)
+	for _, value := range values {
+		fmt.Fprintf(&buf, "%s%s %s = %q\n", prefix, camelCase(value), name, value)
+	}
+	buf.WriteString(")\n")
+
+	return g.declare(name, buf.String(), func() {
+		for _, value := range values {
+			g.consts[prefix+camelCase(value)] = name
+		}
+		g.order = append(g.order, name)
+		g.enums = append(g.enums, name)
+	})
+}
+
+// declare records the declaration of a type. Different schema nodes may map
+// to the same type name as long as they produce the same declaration.
+func (g *generator) declare(name, decl string, onNew func()) error {
+	existing, ok := g.decls[name]
+	switch {
+	case !ok || existing == "":
+		g.decls[name] = decl
+		onNew()
+		return nil
+	case existing != decl:
+		return fmt.Errorf("conflicting declarations for type %s, use -rename to disambiguate", name)
+	default:
+		return nil
+	}
+}
+
+func (g *generator) file(source string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by schemagen from %s. DO NOT EDIT.\n\npackage types\n\n", source)
+	if len(g.structs) > 0 {
+		buf.WriteString("import \"encoding/json\"\n\n")
+	}
+
+	isEnum := make(map[string]bool)
+	for _, name := range g.enums {
+		isEnum[name] = true
+	}
+	for _, name := range g.order {
+		if !isEnum[name] {
+			buf.WriteString(g.decls[name] + "\n")
+		}
+	}
+	for _, name := range g.enums {
+		buf.WriteString(g.decls[name] + "\n")
+	}
+
+	for _, name := range g.order {
+		keys, ok := g.structs[name]
+		if !ok {
+			continue
+		}
+		recv := strings.ToLower(name[:1])
+		quoted := make([]string, len(keys))
+		for idx, key := range keys {
+			quoted[idx] = fmt.Sprintf("%q", key)
+		}
+		fmt.Fprintf(&buf, `func (%[1]s *%[2]s) UnmarshalJSON(data []byte) error {
+	type plain %[2]s
+	extra, err := unmarshalWithExtra(data, (*plain)(%[1]s), %[3]s)
+	if err != nil {
+		return err
+	}
+	%[1]s.Extra = extra
+	return nil
+}
+
+func (%[1]s %[2]s) MarshalJSON() ([]byte, error) {
+	type plain %[2]s
+	return marshalWithExtra(plain(%[1]s), %[1]s.Extra)
+}
+
+`, recv, name, strings.Join(quoted, ", "))
+	}
+	return buf.Bytes()
+}
+
+// initialisms are kept upper case in go identifiers
+var initialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "json": true, "png": true, "rest": true,
+	"rpc": true, "sdk": true, "svg": true, "uri": true, "url": true,
+}
+
+// camelCase converts json keys and enum values into exported go identifiers
+// i.e. "chain_id" -> "ChainID", "secp256k1" -> "Secp256K1"
+func camelCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var out strings.Builder
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			out.WriteString(strings.ToUpper(word))
+			continue
+		}
+		// separate digits that would otherwise run together
+		if out.Len() > 0 && unicode.IsDigit(rune(word[0])) && unicode.IsDigit(rune(out.String()[out.Len()-1])) {
+			out.WriteString("_")
+		}
+		runes := []rune(word)
+		for idx, r := range runes {
+			// capitalise letters following digits, except for a plural "s"
+			if idx == 0 || (unicode.IsDigit(runes[idx-1]) && !(r == 's' && idx == len(runes)-1)) {
+				r = unicode.ToUpper(r)
+			}
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+func writeComment(buf *bytes.Buffer, comment string) {
+	if comment == "" {
+		return
+	}
+	line := "//"
+	for _, word := range strings.Fields(comment) {
+		if len(line)+len(word) > 90 {
+			buf.WriteString(line + "\n")
+			line = "//"
+		}
+		line += " " + word
+	}
+	buf.WriteString(line + "\n")
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,9 @@
+package types
+
+// ListResponse is a page of a list endpoint. It is returned instead of a
+// plain list when any of the list parameters are used.
+type ListResponse struct {
+	Items      interface{} `json:"items"`                 // Names, or objects if expanded
+	NextCursor string      `json:"next_cursor,omitempty"` // Cursor of the next page. Empty on the last page
+	Total      int         `json:"total"`                 // The number of items in all pages
+}
@@ -0,0 +1,10 @@
+package types
+
+// ChainProvenance records which registry layer each field of a chain's
+// chain.json and assetlist.json came from when several registries are merged.
+// Fields are keyed by their dot separated path, i.e. "apis.rpc". Assets are
+// addressed by their base denom, i.e. "assets.uatom.logo_URIs.png".
+type ChainProvenance struct {
+	Chain     map[string]string `json:"chain"`      // field path -> layer name
+	AssetList map[string]string `json:"asset_list"` // field path -> layer name
+}
@@ -0,0 +1,308 @@
+package types
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Documents are validated against the same schemas that the types are
+// generated from. Only the keywords used by the registry's schemas are
+// supported: type, enum, required, properties, additionalProperties, items,
+// uniqueItems, pattern, maxLength, $ref to local definitions and if/then/else.
+// Other keywords, such as format, are ignored.
+
+//go:embed chain.schema.json
+var chainSchemaJSON []byte
+
+//go:embed assetlist.schema.json
+var assetListSchemaJSON []byte
+
+var (
+	chainSchema     = lazySchema(chainSchemaJSON)
+	assetListSchema = lazySchema(assetListSchemaJSON)
+)
+
+// maxValidationErrors bounds the number of errors reported for a document
+const maxValidationErrors = 20
+
+// ValidationError lists the ways in which a document violates its schema
+type ValidationError struct {
+	Errors []string `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid document: " + strings.Join(e.Errors, "; ")
+}
+
+// ValidateChain validates a chain.json document against the chain schema
+func ValidateChain(data []byte) error {
+	return validateDocument(chainSchema, data)
+}
+
+// ValidateAssetList validates an assetlist.json document against the asset
+// list schema
+func ValidateAssetList(data []byte) error {
+	return validateDocument(assetListSchema, data)
+}
+
+func validateDocument(schema func() map[string]interface{}, data []byte) error {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return &ValidationError{Errors: []string{err.Error()}}
+	}
+	root := schema()
+	v := &validator{root: root}
+	v.validate(root, doc, "")
+	if len(v.errors) > 0 {
+		return &ValidationError{Errors: v.errors}
+	}
+	return nil
+}
+
+func lazySchema(data []byte) func() map[string]interface{} {
+	var (
+		once   sync.Once
+		schema map[string]interface{}
+	)
+	return func() map[string]interface{} {
+		once.Do(func() {
+			if err := json.Unmarshal(data, &schema); err != nil {
+				panic(fmt.Sprintf("invalid embedded schema: %v", err))
+			}
+		})
+		return schema
+	}
+}
+
+type validator struct {
+	root   map[string]interface{}
+	errors []string
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	if len(v.errors) >= maxValidationErrors {
+		return
+	}
+	if path == "" {
+		path = "(root)"
+	}
+	v.errors = append(v.errors, path+": "+fmt.Sprintf(format, args...))
+}
+
+// validate checks value against schema, returning whether it is valid
+func (v *validator) validate(schema map[string]interface{}, value interface{}, path string) bool {
+	before := len(v.errors)
+
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "%v", err)
+			return false
+		}
+		return v.validate(resolved, value, path)
+	}
+
+	if types, ok := schema["type"]; ok && !matchesType(types, value) {
+		v.fail(path, "expected %v, got %s", types, jsonType(value))
+		return false
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range enum {
+			if jsonEqual(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "must be one of %v", enum)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, value, path)
+	case []interface{}:
+		v.validateArray(schema, value, path)
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err == nil && !re.MatchString(value) {
+				v.fail(path, "must match %s", pattern)
+			}
+		}
+		if max, ok := schema["maxLength"].(float64); ok && float64(len([]rune(value))) > max {
+			v.fail(path, "must be at most %v characters", max)
+		}
+	}
+
+	if cond, ok := schema["if"].(map[string]interface{}); ok {
+		branch := "else"
+		if v.matches(cond, value, path) {
+			branch = "then"
+		}
+		if sub, ok := schema[branch].(map[string]interface{}); ok {
+			v.validate(sub, value, path)
+		}
+	}
+
+	return len(v.errors) == before
+}
+
+func (v *validator) validateObject(schema map[string]interface{}, value map[string]interface{}, path string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, field := range required {
+			name, _ := field.(string)
+			if _, ok := value[name]; !ok {
+				v.fail(path, "missing required field %s", name)
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := joinSchemaPath(path, key)
+		if sub, ok := properties[key].(map[string]interface{}); ok {
+			v.validate(sub, value[key], child)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(child, "unknown field")
+			}
+		case map[string]interface{}:
+			v.validate(additional, value[key], child)
+		}
+	}
+}
+
+func (v *validator) validateArray(schema map[string]interface{}, value []interface{}, path string) {
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for idx, item := range value {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, idx))
+		}
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if jsonEqual(value[i], value[j]) {
+					v.fail(path, "items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+}
+
+// matches reports whether value satisfies schema without recording errors
+func (v *validator) matches(schema map[string]interface{}, value interface{}, path string) bool {
+	sub := &validator{root: v.root}
+	return sub.validate(schema, value, path)
+}
+
+// resolve looks up a local reference such as "#/$defs/endpoint"
+func (v *validator) resolve(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported schema reference %s", ref)
+	}
+	var node interface{} = v.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolved schema reference %s", ref)
+		}
+		node = obj[strings.NewReplacer("~1", "/", "~0", "~").Replace(part)]
+	}
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unresolved schema reference %s", ref)
+	}
+	return schema, nil
+}
+
+func matchesType(types interface{}, value interface{}) bool {
+	switch types := types.(type) {
+	case string:
+		return isType(types, value)
+	case []interface{}:
+		for _, t := range types {
+			if name, ok := t.(string); ok && isType(name, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isType(name string, value interface{}) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, ok = new(big.Int).SetString(n.String(), 10)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return jsonType(value) == name
+	}
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+func jsonEqual(a, b interface{}) bool {
+	if n, ok := b.(json.Number); ok {
+		// enums in the schema are decoded as float64
+		if f, err := n.Float64(); err == nil {
+			b = f
+		}
+	}
+	if n, ok := a.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			a = f
+		}
+	}
+	ab, _ := json.Marshal(a)
+	bb, _ := json.Marshal(b)
+	return bytes.Equal(ab, bb)
+}
+
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// patchDocument applies a JSON merge patch to a document, removing fields
+// set to null
+func patchDocument(t *testing.T, data []byte, patch string) []byte {
+	t.Helper()
+	var doc, overlay map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(patch), &overlay); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range overlay {
+		if value == nil {
+			delete(doc, key)
+			continue
+		}
+		doc[key] = value
+	}
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return patched
+}
+
+func TestValidateChain(t *testing.T) {
+	data, err := os.ReadFile("testdata/chain.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name  string
+		patch string
+		error string // a substring of the error, empty if the document is valid
+	}{
+		{"upstream document", `{}`, ""},
+		{"unknown field", `{"some_new_field":{"x":1}}`, ""},
+		{"missing chain_id", `{"chain_id":null}`, "(root): missing required field chain_id"},
+		{"wrong type", `{"slip44":"118"}`, "slip44: expected number, got string"},
+		{"not in enum", `{"status":"dead"}`, "status: must be one of"},
+		{"missing nested field", `{"codebase":{"git_repo":"https://github.com/cosmos/gaia"}}`, "codebase: missing required field recommended_version"},
+		{"invalid array item", `{"peers":{"seeds":[{"id":"abc"}]}}`, "peers.seeds[0]: missing required field address"},
+		{"duplicate items", `{"extra_codecs":["ethermint","ethermint"]}`, "extra_codecs: items 0 and 1 are equal"},
+		{"pattern", `{"codebase":{"git_repo":"https://github.com/cosmos/gaia","recommended_version":"v1","compatible_versions":[],"cosmwasm_path":"/data/wasm"}}`,
+			"codebase.cosmwasm_path: must match"},
+		{"too long", `{"description":"` + strings.Repeat("a", 3001) + `"}`, "description: must be at most 3000 characters"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateChain(patchDocument(t, data, tc.patch))
+			checkValidation(t, err, tc.error)
+		})
+	}
+
+	if err := ValidateChain([]byte(`{"chain_name":`)); err == nil {
+		t.Fatal("expected malformed JSON to be rejected")
+	}
+	if err := ValidateChain([]byte(`[]`)); err == nil {
+		t.Fatal("expected an array to be rejected")
+	}
+}
+
+func TestValidateAssetList(t *testing.T) {
+	data, err := os.ReadFile("testdata/assetlist.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name  string
+		patch string
+		error string
+	}{
+		{"upstream document", `{}`, ""},
+		{"missing assets", `{"assets":null}`, "(root): missing required field assets"},
+		{"missing base", `{"assets":[{"display":"atom","denom_units":[]}]}`, "assets[0]: missing required field base"},
+		{"fractional exponent", `{"assets":[{"base":"uatom","display":"atom","denom_units":[{"denom":"uatom","exponent":0.5}]}]}`,
+			"assets[0].denom_units[0].exponent: expected integer, got number"},
+		{"unknown trace type", `{"assets":[{"base":"uatom","display":"atom","denom_units":[],"traces":[{"type":"teleport","counterparty":{"chain_name":"x","base_denom":"y"}}]}]}`,
+			"assets[0].traces[0].type: must be one of"},
+		{"invalid color", `{"assets":[{"base":"uatom","display":"atom","denom_units":[],"images":[{"theme":{"primary_color_hex":"red"}}]}]}`,
+			"assets[0].images[0].theme.primary_color_hex: must match"},
+		{"cw20 without an address", `{"assets":[{"base":"cw20:juno1abc","display":"token","denom_units":[],"kind":"cw20"}]}`,
+			"assets[0]: missing required field address"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAssetList(patchDocument(t, data, tc.patch))
+			checkValidation(t, err, tc.error)
+		})
+	}
+}
+
+func checkValidation(t *testing.T, err error, expected string) {
+	t.Helper()
+	if expected == "" {
+		if err != nil {
+			t.Fatalf("expected the document to be valid, got %v", err)
+		}
+		return
+	}
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	for _, msg := range invalid.Errors {
+		if strings.Contains(msg, expected) {
+			return
+		}
+	}
+	t.Fatalf("expected an error containing %q, got %v", expected, invalid.Errors)
+}
@@ -0,0 +1,16 @@
+package types
+
+import "time"
+
+// ServerStatus reports the state of the registry that the server has loaded
+type ServerStatus struct {
+	Source      string            `json:"source"`           // The registry that the server pulls from as source:location, i.e. "github:cosmos/chain-registry". Layered registries are listed as name=source:location, lowest priority first
+	Commit      string            `json:"commit,omitempty"` // The commit of the registry that is loaded
+	Ready       bool              `json:"ready"`            // Whether the registry has been loaded with at least one chain
+	LastUpdated time.Time         `json:"last_updated"`
+	LastError   string            `json:"last_error,omitempty"` // The error of the last pull if it failed
+	LastErrorAt *time.Time        `json:"last_error_at,omitempty"`
+	ChainErrors map[string]string `json:"chain_errors"` // chain name -> error pulling the chain. The previously pulled version is served if there is one
+	Chains      int               `json:"chains"`
+	Assets      int               `json:"assets"`
+}
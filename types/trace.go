@@ -0,0 +1,26 @@
+package types
+
+// AssetHop is a single location of an asset on the path back to its origin
+type AssetHop struct {
+	ChainName string `json:"chain_name"`
+	Denom     string `json:"denom"`          // The base denom of the asset on this chain
+	Type      string `json:"type,omitempty"` // How the asset arrived on this chain i.e. "ibc" or "bridge". Empty at the origin.
+}
+
+// AssetOrigin is the originating chain and canonical asset of an asset,
+// together with the path of hops taken to get there.
+type AssetOrigin struct {
+	ChainName string       `json:"chain_name"`
+	Asset     AssetElement `json:"asset"`
+	Path      []AssetHop   `json:"path"` // From the requested asset to the origin, inclusive
+}
+
+// AssetRepresentation is a chain on which an asset can be found
+type AssetRepresentation struct {
+	ChainName string  `json:"chain_name"`
+	ChainID   string  `json:"chain_id"`
+	Denom     string  `json:"denom"` // The base denom of the asset on this chain
+	Display   string  `json:"display"`
+	Symbol    *string `json:"symbol,omitempty"`
+	Origin    bool    `json:"origin"` // Whether this is the originating chain of the asset
+}